@@ -0,0 +1,209 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "testing"
+
+func TestAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race detector allocates")
+	}
+	p := newPRF(nil)
+	key := make([]byte, 32)
+	m := make([]byte, 32)
+	out := make([]byte, 32)
+	addrs := make(addr, 32)
+	fs := map[string]func(){
+		"hashF":  func() { hashF(key, m, out) },
+		"hashH":  func() { hashH(key, m, m, out) },
+		"sum":    func() { p.sum(m, out) },
+		"sumInt": func() { p.sumInt(3, out) },
+		"randHash": func() {
+			randHash(key, m, p, addrs, out)
+		},
+		"chain": func() {
+			chain(m, 0, w-1, p, addrs, out)
+		},
+	}
+	for name, f := range fs {
+		if a := testing.AllocsPerRun(100, f); a != 0 {
+			t.Error(name, "allocates", a)
+		}
+	}
+
+	mer := NewMerkle(10, generateSeed())
+	s := &Stack{
+		stack:  make([]*NH, 0, 200),
+		height: 20,
+	}
+	//a leaf allocates only its node.
+	if a := testing.AllocsPerRun(100, func() { s.newleaf(mer.priv, false) }); a > 1 {
+		t.Error("newleaf allocates", a)
+	}
+}
+
+func TestAllocsSignVerify(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race detector allocates")
+	}
+	p := NewPool(1)
+	defer p.Close()
+	mer := NewMerkle(10, generateSeed())
+	mer.SetExecutor(p)
+	msg := []byte("This is a test for allocations.")
+	pk := mer.PublicKey()
+	var sig []byte
+	sign := testing.AllocsPerRun(100, func() {
+		var err error
+		sig, err = mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	verify := testing.AllocsPerRun(100, func() {
+		if !VerifyWith(p, sig, msg, pk) {
+			t.Fatal("XMSS sig is incorrect")
+		}
+	})
+	//Sign allocates only the signature.
+	if sign > 1 {
+		t.Error("Sign allocates", sign)
+	}
+	if verify > 0 {
+		t.Error("Verify allocates", verify)
+	}
+}
+
+func BenchmarkSignAllocs(b *testing.B) {
+	b.ReportAllocs()
+	p := NewPool(1)
+	defer p.Close()
+	seed := generateSeed()
+	mer := NewMerkle(10, seed)
+	mer.SetExecutor(p)
+	msg := []byte("This is a test for allocations.")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if mer.LeafNo() == 1<<10 {
+			b.StopTimer()
+			mer = NewMerkle(10, seed)
+			mer.SetExecutor(p)
+			b.StartTimer()
+		}
+		if _, err := mer.Sign(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyAllocs(b *testing.B) {
+	b.ReportAllocs()
+	p := NewPool(1)
+	defer p.Close()
+	mer := NewMerkle(10, generateSeed())
+	msg := []byte("This is a test for allocations.")
+	pk := mer.PublicKey()
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !VerifyWith(p, sig, msg, pk) {
+			b.Fatal("XMSS sig is incorrect")
+		}
+	}
+}
+
+func TestAllocsSignVerifyMT(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race detector allocates")
+	}
+	p := NewPool(1)
+	defer p.Close()
+	mt, err := NewPrivKeyMT(generateSeed(), 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt.SetExecutor(p)
+	msg := []byte("This is a test for allocations.")
+	pk := mt.PublicKey()
+	var sig []byte
+	sign := testing.AllocsPerRun(100, func() {
+		var err error
+		sig, err = mt.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	verify := testing.AllocsPerRun(100, func() {
+		if !VerifyMTWith(p, sig, msg, pk) {
+			t.Fatal("XMSS^MT sig is incorrect")
+		}
+	})
+	//Sign allocates only the signature.
+	if sign > 1 {
+		t.Error("Sign allocates", sign)
+	}
+	if verify > 0 {
+		t.Error("VerifyMT allocates", verify)
+	}
+}
+
+func BenchmarkSignMTAllocs(b *testing.B) {
+	b.ReportAllocs()
+	p := NewPool(1)
+	defer p.Close()
+	mt, err := NewPrivKeyMT(generateSeed(), 20, 2)
+	if err != nil {
+		b.Fatal(err)
+	}
+	mt.SetExecutor(p)
+	msg := []byte("This is a test for allocations.")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mt.Sign(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyMTAllocs(b *testing.B) {
+	b.ReportAllocs()
+	p := NewPool(1)
+	defer p.Close()
+	mt, err := NewPrivKeyMT(generateSeed(), 20, 2)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte("This is a test for allocations.")
+	pk := mt.PublicKey()
+	sig, err := mt.Sign(msg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !VerifyMTWith(p, sig, msg, pk) {
+			b.Fatal("XMSS^MT sig is incorrect")
+		}
+	}
+}
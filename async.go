@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+//SetAsync sets whether traversing for the next leaf after Sign runs in background.
+//If on, Sign returns right after signing, and the next Sign, or any other method which
+//uses the traversal state, waits for the traversal to finish.
+//Merkle is still not safe for concurrent use.
+func (m *Merkle) SetAsync(on bool) {
+	if !on {
+		m.Flush()
+	}
+	m.async = on
+}
+
+//Flush waits for traversing in background to finish.
+//Call this before reading or persisting the state by other means than methods of Merkle.
+func (m *Merkle) Flush() {
+	if m.done != nil {
+		<-m.done
+		m.done = nil
+	}
+}
+
+//advance moves to the next leaf after signing.
+//The leaf number is increased before returning,
+//so the used leaf is never used again even if m is saved right after this.
+func (m *Merkle) advance() {
+	if !m.async {
+		m.Traverse()
+		return
+	}
+	leaf := m.Leaf
+	m.Leaf++
+	m.epoch++
+	done := make(chan struct{})
+	m.done = done
+	go func() {
+		m.refreshAuth(leaf)
+		m.build()
+		close(done)
+	}()
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestAsync(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for asynchronous traversal.")
+	mer := NewMerkle(5, seed)
+	amer := NewMerkle(5, seed)
+	amer.SetAsync(true)
+	for i := 0; i < 1<<5; i++ {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		asig, err := amer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, asig) {
+			t.Fatal("signatures differ", i)
+		}
+		if amer.LeafNo() != uint64(i+1) {
+			t.Error("leaf no must be increased", amer.LeafNo())
+		}
+		if i == 10 {
+			dat, err := json.Marshal(mer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			adat, err := json.Marshal(amer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dat, adat) {
+				t.Error("states differ")
+			}
+		}
+	}
+	if _, err := amer.Sign(msg); err == nil {
+		t.Error("should not sign with exhausted key")
+	}
+
+	amer = NewMerkle(5, seed)
+	amer.SetAsync(true)
+	if _, err := amer.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	amer.Destroy()
+	if _, err := amer.Sign(msg); err != ErrDestroyed {
+		t.Error("should not sign after destroyed", err)
+	}
+}
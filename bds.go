@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "errors"
+
+//In BDS traversal with parameter k, all nodes in the top k levels of the tree
+//(except the root) are computed at key generation and retained,
+//so that treehash stacks are needed only for the lower h-k levels.
+//This trades the size of the state (2^(k+1)-2 retained nodes)
+//against the work per Traverse (2(h-k)-1 treehash updates).
+
+//retainSize returns the number of retained nodes for k.
+func retainSize(k uint32) uint64 {
+	return (1 << (k + 1)) - 2
+}
+
+//retainIndex returns the position of the node at height and index in retain.
+//Nodes are stored from the lowest retained level.
+func retainIndex(h, k, height, index uint32) uint64 {
+	return (1 << (k + 1)) - (1 << (h - height + 1)) + uint64(index)
+}
+
+//retained returns the retained node at height and index,
+//or nil if the index is beyond the tree.
+func (m *Merkle) retained(height, index uint32) []byte {
+	if uint64(index) >= 1<<(m.Height-height) {
+		return nil
+	}
+	return m.retain[retainIndex(m.Height, m.k, height, index)]
+}
+
+//NewMerkleK makes Merkle struct from height and private seed,
+//retaining all nodes in the top k levels of the tree.
+//Larger k makes Traverse (and Sign) faster and the state larger.
+//See TraversalCost for details.
+//h must be from 1 to 32, and k must not be larger than 16 as in NewMerkleFull.
+func NewMerkleK(h, k byte, seed []byte) (*Merkle, error) {
+	if h == 0 || h > maxHeight {
+		return nil, errors.New("height must be from 1 to 32")
+	}
+	if k > h {
+		return nil, errors.New("k must not be larger than height")
+	}
+	if k > maxFullHeight {
+		return nil, errors.New("k must not be larger than 16")
+	}
+	if err := checkSelfTest(); err != nil {
+		return nil, err
+	}
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	m := newMerkleK(uint32(h), uint32(k), wotsSeed, msgSeed, pubSeed, 0, 0, nil)
+	wipe(wotsSeed)
+	wipe(msgSeed)
+	return m, nil
+}
+
+//K returns the number of top levels whose nodes are retained.
+func (m *Merkle) K() uint32 {
+	return m.k
+}
+
+//Cost is the cost of traversing a Merkle tree.
+type Cost struct {
+	//Nodes is the max number of nodes (32 bytes each) in authes, stacks and retained nodes.
+	Nodes uint64
+	//Updates is the number of treehash updates per Traverse in the worst case.
+	//An update computes a leaf (a WOTS+ public key and its L-tree) or a node.
+	Updates uint64
+}
+
+//TraversalCost returns the cost of traversing a tree with height h
+//which retains top k levels.
+func TraversalCost(h, k uint32) Cost {
+	c := Cost{
+		Nodes: uint64(h) + retainSize(k),
+	}
+	for i := uint32(0); i < h-k; i++ {
+		c.Nodes += uint64(i) + 1
+	}
+	if h > k {
+		c.Updates = 2*uint64(h-k) - 1
+	}
+	return c
+}
+
+//Cost returns the cost of traversing m.
+func (m *Merkle) Cost() Cost {
+	return TraversalCost(m.Height, m.k)
+}
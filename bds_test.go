@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBDS(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for BDS traversal.")
+	mers := make([]*Merkle, 0, 7)
+	for k := byte(0); k <= 6; k++ {
+		mer, err := NewMerkleK(6, k, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mer.K() != uint32(k) {
+			t.Error("invalid k", mer.K())
+		}
+		mers = append(mers, mer)
+	}
+	for i := 0; i < 1<<6; i++ {
+		sig0, err := mers[0].Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(sig0, msg, mers[0].PublicKey()) {
+			t.Error("XMSS sig is incorrect", i)
+		}
+		for k, mer := range mers[1:] {
+			sig, err := mer.Sign(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, sig0) {
+				t.Fatal("signatures differ", i, k+1)
+			}
+		}
+	}
+	if _, err := NewMerkleK(6, 7, seed); err == nil {
+		t.Error("should not make Merkle with k > height")
+	}
+	if _, err := NewMerkleK(20, 20, seed); err == nil {
+		t.Error("should not make Merkle with k > 16")
+	}
+	if _, err := NewMerkleK(33, 4, seed); err == nil {
+		t.Error("should not make Merkle with height > 32")
+	}
+	if _, err := NewMerkleK(0, 0, seed); err == nil {
+		t.Error("should not make Merkle with height 0")
+	}
+}
+
+func TestBDSMarshalAndSetLeafNo(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for BDS traversal.")
+	mer, err := NewMerkleK(8, 3, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mer0 := NewMerkle(8, seed)
+	if err := mer.SetLeafNo(100); err != nil {
+		t.Fatal(err)
+	}
+	if err := mer0.SetLeafNo(100); err != nil {
+		t.Fatal(err)
+	}
+	dat, err := json.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mer2 Merkle
+	if err := json.Unmarshal(dat, &mer2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		sig0, err := mer0.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := mer2.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sig0) {
+			t.Fatal("signatures differ", i)
+		}
+	}
+}
+
+func TestTraversalCost(t *testing.T) {
+	c := TraversalCost(10, 0)
+	if c.Nodes != 10+55 || c.Updates != 19 {
+		t.Error("invalid cost", c)
+	}
+	c = TraversalCost(10, 4)
+	if c.Nodes != 10+30+21 || c.Updates != 11 {
+		t.Error("invalid cost", c)
+	}
+	c = TraversalCost(10, 10)
+	if c.Nodes != 10+2046 || c.Updates != 0 {
+		t.Error("invalid cost", c)
+	}
+}
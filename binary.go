@@ -0,0 +1,549 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "encoding/binary"
+
+//Binary encoding of NH, Stack, PrivKey, Merkle and PrivKeyMT (by MarshalBinary) is:
+//
+//	version (1 byte) | kind (1 byte) | hash (1 byte, 1 for SHA2-256) | n (1 byte) | w (1 byte) | body
+//
+//where kind is 'N', 'S', 'K', 'M' or 'T' for NH, Stack, PrivKey, Merkle and PrivKeyMT.
+//Integers are in big endian, and each node, seed and tag is n bytes. Bodies in version 1 are:
+//
+//	NH:        height (4) | index (4) | node
+//	Stack:     height (4) | leaf (4) | layer (4) | tree (8) | number of NHs (4) | NH bodies
+//	PrivKey:   message seed | WOTS+ seed | public seed | root
+//	Merkle:    height (4) | k (4) | layer (4) | tree (8) | leaf (4) | start (8) | end (8) | tag |
+//	           PrivKey body | auth (height nodes) | Stack bodies (height-k) |
+//	           retained nodes (2^(k+1)-2) | bitmap of used leaves (2^height/8 bytes if k = height)
+//	PrivKeyMT: h (4) | d (4) | index (8) | start (8) | end (8) | tag |
+//	           d * (1 byte which is 1 if the Merkle of the layer exists | Merkle body)
+//
+//Version 2 appends epoch (8) | MAC of the state to bodies of Merkle and PrivKeyMT (see epoch.go).
+//Version 3 inserts suspect (1 byte, 1 if the state is suspect, see guard.go) between them,
+//so that MAC covers it.
+//
+//UnmarshalBinary decodes all versions which were ever written, migrating them to the current state.
+//States of Merkle and PrivKeyMT in version 1 have no MAC and are rejected.
+//States in version 2 are migrated as not suspect, and MAC is checked over the body in version 2.
+
+const (
+	binaryVersion = 3
+	binarySHA256  = 1
+
+	kindNH        = 'N'
+	kindStack     = 'S'
+	kindPrivKey   = 'K'
+	kindMerkle    = 'M'
+	kindPrivKeyMT = 'T'
+)
+
+//encoder appends values to b.
+type encoder struct {
+	b []byte
+	//v is the version of bodies to encode, or the current version if 0.
+	v byte
+}
+
+func (e *encoder) version() byte {
+	if e.v == 0 {
+		return binaryVersion
+	}
+	return e.v
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.u8(1)
+	} else {
+		e.u8(0)
+	}
+}
+
+func (e *encoder) u8(v byte) {
+	e.b = append(e.b, v)
+}
+
+func (e *encoder) u32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.b = append(e.b, b[:]...)
+}
+
+func (e *encoder) u64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.b = append(e.b, b[:]...)
+}
+
+//node appends v which must be n bytes.
+func (e *encoder) node(v []byte) {
+	e.b = append(e.b, v[:n]...)
+}
+
+func (e *encoder) header(kind byte) {
+	e.b = append(e.b, binaryVersion, kind, binarySHA256, n, w)
+}
+
+//decoder reads values from b. After an error, it keeps the error and returns zeros.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+const errShortBinary = ValidationError("binary is too short")
+
+//fits returns true if b has l more bytes.
+func (d *decoder) fits(l uint64) bool {
+	if d.err == nil && uint64(len(d.b)) < l {
+		d.err = errShortBinary
+	}
+	return d.err == nil
+}
+
+func (d *decoder) u8() byte {
+	if !d.fits(1) {
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) u32() uint32 {
+	if !d.fits(4) {
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v
+}
+
+func (d *decoder) u64() uint64 {
+	if !d.fits(8) {
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.b)
+	d.b = d.b[8:]
+	return v
+}
+
+//bytes returns a copy of the next l bytes.
+func (d *decoder) bytes(l uint64) []byte {
+	if !d.fits(l) {
+		return nil
+	}
+	v := make([]byte, l)
+	copy(v, d.b)
+	d.b = d.b[l:]
+	return v
+}
+
+func (d *decoder) node() []byte {
+	return d.bytes(n)
+}
+
+//header reads the header and returns the version.
+func (d *decoder) header(kind byte) byte {
+	if !d.fits(5) {
+		return 0
+	}
+	v := d.b[0]
+	switch {
+	case v == 0 || v > binaryVersion:
+		d.err = ValidationError("unsupported version of binary")
+	case d.b[1] != kind:
+		d.err = ValidationError("invalid kind of binary")
+	case d.b[2] != binarySHA256 || d.b[3] != n || d.b[4] != w:
+		d.err = ValidationError("unsupported parameters in binary")
+	}
+	d.b = d.b[5:]
+	return v
+}
+
+//finish returns the error, or an error if bytes are left.
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.b) != 0 {
+		d.err = ValidationError("binary is too long")
+	}
+	return d.err
+}
+
+func (nn *NH) encode(e *encoder) {
+	e.u32(nn.height)
+	e.u32(nn.index)
+	e.node(nn.node)
+}
+
+func decodeNH(d *decoder) *NH {
+	var nn NH
+	err := nn.imports(&nh{
+		Height: d.u32(),
+		Index:  d.u32(),
+		Node:   d.node(),
+	})
+	if d.err == nil {
+		d.err = err
+	}
+	return &nn
+}
+
+//MarshalBinary marshals NH into the binary encoding.
+func (nn *NH) MarshalBinary() ([]byte, error) {
+	e := &encoder{}
+	e.header(kindNH)
+	nn.encode(e)
+	return e.b, nil
+}
+
+//UnmarshalBinary unmarshals the binary encoding to NH.
+func (nn *NH) UnmarshalBinary(b []byte) error {
+	d := &decoder{b: b}
+	d.header(kindNH)
+	v := decodeNH(d)
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*nn = *v
+	return nil
+}
+
+func (s *Stack) encode(e *encoder) {
+	e.u32(s.height)
+	e.u32(s.leaf)
+	e.u32(s.layer)
+	e.u64(s.tree)
+	e.u32(uint32(len(s.stack)))
+	for _, nn := range s.stack {
+		nn.encode(e)
+	}
+}
+
+func decodeStack(d *decoder) *Stack {
+	sr := stack{
+		Height: d.u32(),
+		Leaf:   d.u32(),
+		Layer:  d.u32(),
+		Tree:   d.u64(),
+	}
+	num := d.u32()
+	if d.err == nil && num > sr.Height+1 {
+		d.err = ValidationError("too many nodes in stack")
+	}
+	if d.fits(uint64(num) * (4 + 4 + n)) {
+		sr.Stack = make([]*NH, num)
+		for i := range sr.Stack {
+			sr.Stack[i] = decodeNH(d)
+		}
+	}
+	var s Stack
+	if err := s.imports(&sr); d.err == nil {
+		d.err = err
+	}
+	return &s
+}
+
+//MarshalBinary marshals Stack into the binary encoding.
+func (s *Stack) MarshalBinary() ([]byte, error) {
+	e := &encoder{}
+	e.header(kindStack)
+	s.encode(e)
+	return e.b, nil
+}
+
+//UnmarshalBinary unmarshals the binary encoding to Stack.
+func (s *Stack) UnmarshalBinary(b []byte) error {
+	d := &decoder{b: b}
+	d.header(kindStack)
+	v := decodeStack(d)
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*s = *v
+	return nil
+}
+
+func (x *PrivKey) encode(e *encoder) {
+	e.node(x.msgPRF.seed)
+	e.node(x.wotsPRF.seed)
+	e.node(x.pubPRF.seed)
+	e.node(x.root)
+}
+
+func decodePrivKey(d *decoder) *PrivKey {
+	s := privkey{
+		MsgSeed:  d.node(),
+		WotsSeed: d.node(),
+		PubSeed:  d.node(),
+		Root:     d.node(),
+	}
+	if d.err != nil {
+		wipe(s.MsgSeed)
+		wipe(s.WotsSeed)
+		return nil
+	}
+	var x PrivKey
+	if err := x.imports(&s); err != nil {
+		d.err = err
+		return nil
+	}
+	return &x
+}
+
+//MarshalBinary marshals PrivKey into the binary encoding.
+func (x *PrivKey) MarshalBinary() ([]byte, error) {
+	if x.destroyed {
+		return nil, ErrDestroyed
+	}
+	e := &encoder{}
+	e.header(kindPrivKey)
+	x.encode(e)
+	return e.b, nil
+}
+
+//UnmarshalBinary unmarshals the binary encoding to PrivKey.
+func (x *PrivKey) UnmarshalBinary(b []byte) error {
+	d := &decoder{b: b}
+	d.header(kindPrivKey)
+	v := decodePrivKey(d)
+	if err := d.finish(); err != nil {
+		if v != nil {
+			v.Destroy()
+		}
+		return err
+	}
+	*x = *v
+	return nil
+}
+
+//encode appends the body of m with its MAC.
+func (m *Merkle) encode(e *encoder) {
+	m.encodeBody(e)
+	e.node(m.mac(e.version()))
+}
+
+//encodeBody appends the body of m without its MAC.
+func (m *Merkle) encodeBody(e *encoder) {
+	e.u32(m.Height)
+	e.u32(m.k)
+	e.u32(m.layer)
+	e.u64(m.tree)
+	e.u32(m.Leaf)
+	e.u64(m.start)
+	e.u64(m.end)
+	e.node(m.tag)
+	m.priv.encode(e)
+	for _, a := range m.auth {
+		e.node(a)
+	}
+	for _, s := range m.stacks {
+		s.encode(e)
+	}
+	for _, r := range m.retain {
+		e.node(r)
+	}
+	e.b = append(e.b, m.used...)
+	e.u64(m.epoch)
+	if e.version() >= 3 {
+		e.bool(m.suspect)
+	}
+}
+
+//decodeMerkle decodes the body of Merkle in version v into its mirror.
+//Validation is done by imports.
+func decodeMerkle(d *decoder, v byte) *merkle {
+	s := &merkle{
+		Height: d.u32(),
+		K:      d.u32(),
+		Layer:  d.u32(),
+		Tree:   d.u64(),
+		Leaf:   d.u32(),
+		Start:  d.u64(),
+		End:    d.u64(),
+		Tag:    d.node(),
+	}
+	if d.err == nil && (s.Height > nodesMaxHeight || s.K > s.Height) {
+		d.err = ValidationError("invalid height or k in binary")
+	}
+	s.Priv = decodePrivKey(d)
+	if d.fits(uint64(s.Height) * n) {
+		s.Auth = make([][]byte, s.Height)
+		for i := range s.Auth {
+			s.Auth[i] = d.node()
+		}
+	}
+	if d.err == nil {
+		s.Stacks = make([]*Stack, s.Height-s.K)
+		for i := range s.Stacks {
+			s.Stacks[i] = decodeStack(d)
+		}
+	}
+	if d.fits(retainSize(s.K) * n) {
+		s.Retain = make([][]byte, retainSize(s.K))
+		for i := range s.Retain {
+			s.Retain[i] = d.node()
+		}
+	}
+	if d.err == nil && s.K == s.Height {
+		s.Used = d.bytes((1<<s.Height + 7) / 8)
+	}
+	if v >= 2 {
+		s.Epoch = d.u64()
+	}
+	if v >= 3 {
+		suspect := d.u8() != 0
+		s.Suspect = &suspect
+	}
+	if v >= 2 {
+		s.MAC = d.node()
+	}
+	s.version = v
+	return s
+}
+
+//MarshalBinary marshals Merkle into the binary encoding.
+func (m *Merkle) MarshalBinary() ([]byte, error) {
+	m.Flush()
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
+	e := &encoder{}
+	e.header(kindMerkle)
+	m.encode(e)
+	return e.b, nil
+}
+
+//UnmarshalBinary unmarshals the binary encoding to Merkle.
+func (m *Merkle) UnmarshalBinary(b []byte) error {
+	m.Flush()
+	d := &decoder{b: b}
+	v := d.header(kindMerkle)
+	s := decodeMerkle(d, v)
+	if err := d.finish(); err != nil {
+		if s.Priv != nil {
+			s.Priv.Destroy()
+		}
+		return err
+	}
+	return m.imports(s)
+}
+
+//encode appends the body of p with its MAC.
+func (p *PrivKeyMT) encode(e *encoder) {
+	p.encodeBody(e)
+	e.node(p.mac(e.version()))
+}
+
+//encodeBody appends the body of p without its MAC.
+func (p *PrivKeyMT) encodeBody(e *encoder) {
+	e.u32(p.h)
+	e.u32(p.d)
+	e.u64(p.index)
+	e.u64(p.start)
+	e.u64(p.end)
+	e.node(p.tag)
+	for _, m := range p.merkle {
+		if m == nil {
+			e.u8(0)
+			continue
+		}
+		e.u8(1)
+		m.encode(e)
+	}
+	e.u64(p.epoch)
+	if e.version() >= 3 {
+		e.bool(p.suspect)
+	}
+}
+
+//MarshalBinary marshals PrivKeyMT into the binary encoding.
+func (p *PrivKeyMT) MarshalBinary() ([]byte, error) {
+	if p.destroyed() {
+		return nil, ErrDestroyed
+	}
+	e := &encoder{}
+	e.header(kindPrivKeyMT)
+	p.encode(e)
+	return e.b, nil
+}
+
+//UnmarshalBinary unmarshals the binary encoding to PrivKeyMT.
+func (p *PrivKeyMT) UnmarshalBinary(b []byte) error {
+	d := &decoder{b: b}
+	v := d.header(kindPrivKeyMT)
+	s := &privKeyMT{
+		H:     d.u32(),
+		D:     d.u32(),
+		Index: d.u64(),
+		Start: d.u64(),
+		End:   d.u64(),
+		Tag:   d.node(),
+	}
+	if _, err := PublickeyMTHeader(s.H, s.D); err != nil && d.err == nil {
+		d.err = err
+	}
+	if d.err == nil {
+		s.Merkle = make([]*Merkle, s.D)
+	}
+	for i := range s.Merkle {
+		if d.u8() != 1 {
+			continue
+		}
+		ms := decodeMerkle(d, v)
+		if d.err == nil && ms.Height != s.H/s.D {
+			d.err = ValidationError("invalid height of Merkle in binary")
+		}
+		if d.err != nil {
+			if ms.Priv != nil {
+				ms.Priv.Destroy()
+			}
+			break
+		}
+		s.Merkle[i] = &Merkle{}
+		if err := s.Merkle[i].imports(ms); err != nil {
+			d.err = err
+		}
+	}
+	if v >= 2 {
+		s.Epoch = d.u64()
+	}
+	if v >= 3 {
+		suspect := d.u8() != 0
+		s.Suspect = &suspect
+	}
+	if v >= 2 {
+		s.MAC = d.node()
+	}
+	s.version = v
+	if err := d.finish(); err != nil {
+		for _, m := range s.Merkle {
+			if m != nil {
+				m.priv.Destroy()
+			}
+		}
+		return err
+	}
+	if s.Merkle[s.D-1] == nil {
+		return ValidationError("no Merkle of the top layer")
+	}
+	return p.imports(s)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBinaryMerkle(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for binary encoding.")
+	for _, k := range []byte{0, 2, 5} {
+		mer, err := NewMerkleK(5, k, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 7; i++ {
+			if _, err := mer.Sign(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if k == 5 {
+			if _, err := mer.SignAt(20, msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		b, err := mer.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mer2 Merkle
+		if err := mer2.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		dat, err := json.Marshal(mer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dat2, err := json.Marshal(&mer2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(dat, dat2) {
+			t.Error("states differ", k)
+		}
+		if len(b) >= len(dat) {
+			t.Error("binary must be smaller than JSON", len(b), len(dat))
+		}
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := mer2.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sig2) {
+			t.Error("signatures differ", k)
+		}
+
+		for _, bad := range [][]byte{
+			b[:len(b)-1],
+			append(append([]byte{}, b...), 0),
+			append([]byte{binaryVersion + 1}, b[1:]...),
+			append([]byte{b[0], kindStack}, b[2:]...),
+			append([]byte{b[0], b[1], b[2], 16}, b[4:]...),
+		} {
+			if err := mer2.UnmarshalBinary(bad); err == nil {
+				t.Error("should not unmarshal invalid binary", k)
+			}
+		}
+		//tamper the end of the range.
+		bad := append([]byte{}, b...)
+		bad[5+4+4+4+8+4+8+7] ^= 1
+		if err := mer2.UnmarshalBinary(bad); err == nil {
+			t.Error("should not unmarshal tampered range", k)
+		}
+	}
+}
+
+func TestBinaryOthers(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for binary encoding of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	b, err := mt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mt2 PrivKeyMT
+	if err := mt2.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mt.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := mt2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Error("signatures differ")
+	}
+	if err := mt2.UnmarshalBinary(b[:len(b)-3]); err == nil {
+		t.Error("should not unmarshal invalid binary")
+	}
+
+	s := mt.merkle[0].stacks[2]
+	b, err = s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s2 Stack
+	if err := s2.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	dat, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dat2, err := json.Marshal(&s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dat, dat2) {
+		t.Error("stacks differ")
+	}
+	var nn NH
+	if err := nn.UnmarshalBinary(b); err == nil {
+		t.Error("should not unmarshal Stack to NH")
+	}
+
+	priv := mt.merkle[1].priv
+	b, err = priv.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var priv2 PrivKey
+	if err := priv2.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.wotsPRF.seed, priv2.wotsPRF.seed) || !bytes.Equal(priv.root, priv2.root) {
+		t.Error("private keys differ")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+//VerifyCache remembers roots of subtrees in XMSS^MT which were authenticated
+//by verified signatures. Verifying another signature from the same subtree
+//stops when it reaches the remembered root, instead of computing all upper layers.
+//It is safe for concurrent use.
+type VerifyCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]struct{}
+	//order is the order of entries to be evicted.
+	order []string
+}
+
+//verifiedRoot is a root of the tree in a layer.
+type verifiedRoot struct {
+	layer uint32
+	tree  uint64
+	root  []byte
+}
+
+//NewVerifyCache returns VerifyCache which remembers at most size roots.
+func NewVerifyCache(size int) *VerifyCache {
+	return &VerifyCache{
+		size:    size,
+		entries: make(map[string]struct{}, size),
+	}
+}
+
+func (r *verifiedRoot) key(pk []byte) string {
+	b := make([]byte, len(pk)+4+8+len(r.root))
+	copy(b, pk)
+	binary.BigEndian.PutUint32(b[len(pk):], r.layer)
+	binary.BigEndian.PutUint64(b[len(pk)+4:], r.tree)
+	copy(b[len(pk)+12:], r.root)
+	return string(b)
+}
+
+func (c *VerifyCache) has(pk []byte, r *verifiedRoot) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.entries[r.key(pk)]
+	return ok
+}
+
+func (c *VerifyCache) add(pk []byte, roots []*verifiedRoot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	//roots in lower layers are added last to be evicted last,
+	//because they shorten verification most.
+	for i := len(roots) - 1; i >= 0; i-- {
+		r := roots[i]
+		if c.size <= 0 {
+			return
+		}
+		k := r.key(pk)
+		if _, ok := c.entries[k]; ok {
+			continue
+		}
+		if len(c.order) >= c.size {
+			delete(c.entries, c.order[0])
+			c.order[0] = ""
+			c.order = c.order[1:]
+		}
+		c.entries[k] = struct{}{}
+		c.order = append(c.order, k)
+	}
+}
+
+//Len returns the number of remembered roots.
+func (c *VerifyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.order)
+}
+
+//VerifyMT verifies msg by XMSS^MT like VerifyMT, using and updating the cache.
+func (c *VerifyCache) VerifyMT(bsig, msg, bpk []byte) bool {
+	return verifyMT(nil, bsig, msg, bpk, c)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "testing"
+
+func TestVerifyCache(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for caching verified roots.")
+	sigs := make([][]byte, 3)
+	for i := range sigs {
+		sigs[i], err = mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := NewVerifyCache(100)
+	if c.VerifyMT(sigs[0], []byte("wrong"), mer.PublicKey()) {
+		t.Error("should not verify wrong message")
+	}
+	if c.Len() != 0 {
+		t.Error("roots must not be remembered by failed verification", c.Len())
+	}
+	if !c.VerifyMT(sigs[0], msg, mer.PublicKey()) {
+		t.Error("XMSS^MT sig is incorrect")
+	}
+	if c.Len() != 3 {
+		t.Error("invalid number of remembered roots", c.Len())
+	}
+
+	//the top layer is not used when the root in layer 0 was authenticated.
+	bytesPerLayer := (wlen + 5) * n
+	sigs[1][8+n+bytesPerLayer*3] ^= 1
+	if VerifyMT(sigs[1], msg, mer.PublicKey()) {
+		t.Error("should not verify broken sig without cache")
+	}
+	if !c.VerifyMT(sigs[1], msg, mer.PublicKey()) {
+		t.Error("should verify by the remembered root")
+	}
+	if c.VerifyMT(sigs[2], []byte("wrong"), mer.PublicKey()) {
+		t.Error("should not verify wrong message")
+	}
+	if !c.VerifyMT(sigs[2], msg, mer.PublicKey()) {
+		t.Error("XMSS^MT sig is incorrect")
+	}
+
+	mer2, err := NewPrivKeyMT(generateSeed(), 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.VerifyMT(sigs[2], msg, mer2.PublicKey()) {
+		t.Error("should not verify with other key")
+	}
+
+	c = NewVerifyCache(2)
+	if !c.VerifyMT(sigs[0], msg, mer.PublicKey()) {
+		t.Error("XMSS^MT sig is incorrect")
+	}
+	if c.Len() != 2 {
+		t.Error("invalid number of remembered roots", c.Len())
+	}
+}
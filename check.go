@@ -0,0 +1,146 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+//CorruptError is returned by Check when parts of the state do not match the seeds.
+type CorruptError struct {
+	//Layer is the layer of the corrupt Merkle in PrivKeyMT.
+	Layer uint32
+	//Root is true if the root computed from the current leaf and the auth path
+	//does not match the root in the public key.
+	Root bool
+	//Auth holds heights of wrong nodes in the auth path.
+	Auth []uint32
+	//Stacks holds heights of treehash stacks whose top nodes are wrong.
+	Stacks []uint32
+}
+
+func (e *CorruptError) Error() string {
+	s := fmt.Sprintf("state of layer %d is corrupt:", e.Layer)
+	if e.Root {
+		s += " root does not match the auth path,"
+	}
+	if len(e.Auth) > 0 {
+		s += fmt.Sprintf(" auth at heights %v,", e.Auth)
+	}
+	if len(e.Stacks) > 0 {
+		s += fmt.Sprintf(" stacks at heights %v,", e.Stacks)
+	}
+	return s[:len(s)-1]
+}
+
+//Check recomputes parts of the state of m from the seeds and compares them with the state.
+//The node of the current leaf is always recomputed and hashed up with the auth path,
+//which must give the root in PublicKey(). In addition, samples nodes chosen at random
+//from the auth path and the tops of treehash stacks for nodes in the tree are recomputed,
+//or all of them if samples is negative.
+//Recomputing a node at height h costs 2^h leaves.
+//It returns *CorruptError which reports all wrong parts if found.
+//If samples is negative and no wrong part is found, m is no longer suspect (see SetGuard).
+func (m *Merkle) Check(samples int) error {
+	m.Flush()
+	if m.priv.destroyed {
+		return ErrDestroyed
+	}
+	e := &CorruptError{
+		Layer: m.layer,
+	}
+	//nodes are recomputed with the auth path only if a leaf is left.
+	hasAuth := uint64(m.Leaf) < 1<<m.Height
+	type target struct {
+		auth   bool
+		height uint32
+	}
+	var targets []target
+	if hasAuth {
+		node := m.treeNode(0, m.Leaf)
+		if !bytes.Equal(rootFromAuth(m.Leaf, node, m.auth, m.priv.pubPRF, m.layer, m.tree), m.priv.root) {
+			e.Root = true
+		}
+		for h := uint32(0); h < m.Height; h++ {
+			targets = append(targets, target{true, h})
+		}
+	}
+	for h, s := range m.stacks {
+		//a stack for a node outside the tree holds a placeholder, which is never used.
+		idx := ((m.Leaf >> uint32(h)) + 1) ^ 1
+		if len(s.stack) > 0 && uint64(idx) < 1<<(m.Height-uint32(h)) {
+			targets = append(targets, target{false, uint32(h)})
+		}
+	}
+	if samples >= 0 && samples < len(targets) {
+		sampled := make([]target, samples)
+		for i, j := range rand.Perm(len(targets))[:samples] {
+			sampled[i] = targets[j]
+		}
+		targets = sampled
+	}
+	for _, t := range targets {
+		if t.auth {
+			if !bytes.Equal(m.auth[t.height], m.treeNode(t.height, (m.Leaf>>t.height)^1)) {
+				e.Auth = append(e.Auth, t.height)
+			}
+			continue
+		}
+		top := m.stacks[t.height].top()
+		if uint64(top.index) >= 1<<(m.Height-top.height) ||
+			!bytes.Equal(top.node, m.treeNode(top.height, top.index)) {
+			e.Stacks = append(e.Stacks, t.height)
+		}
+	}
+	if !e.Root && len(e.Auth) == 0 && len(e.Stacks) == 0 {
+		if samples < 0 {
+			m.suspect = false
+		}
+		return nil
+	}
+	sort.Slice(e.Auth, func(i, j int) bool { return e.Auth[i] < e.Auth[j] })
+	sort.Slice(e.Stacks, func(i, j int) bool { return e.Stacks[i] < e.Stacks[j] })
+	return e
+}
+
+//Check checks the Merkle of each layer in p by Merkle.Check with samples.
+//It returns *CorruptError of the lowest corrupt layer if found.
+//If samples is negative and no wrong part is found, p is no longer suspect (see SetGuard).
+func (p *PrivKeyMT) Check(samples int) error {
+	if p.destroyed() {
+		return ErrDestroyed
+	}
+	for _, m := range p.merkle {
+		if m == nil {
+			continue
+		}
+		if err := m.Check(samples); err != nil {
+			return err
+		}
+	}
+	if samples < 0 {
+		p.suspect = false
+	}
+	return nil
+}
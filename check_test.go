@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for checking states.")
+	mer := NewMerkle(6, seed)
+	for i := 0; i < 11; i++ {
+		if _, err := mer.Sign(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mer.Check(-1); err != nil {
+		t.Fatal(err)
+	}
+	if err := mer.Check(0); err != nil {
+		t.Fatal(err)
+	}
+
+	mer.auth[2][0] ^= 1
+	err := mer.Check(-1)
+	ce, ok := err.(*CorruptError)
+	if !ok {
+		t.Fatal("should detect a wrong auth node", err)
+	}
+	if !ce.Root || !reflect.DeepEqual(ce.Auth, []uint32{2}) || len(ce.Stacks) != 0 {
+		t.Error("invalid report", ce)
+	}
+	if err := mer.Check(0); err == nil {
+		t.Error("should detect a wrong auth path without samples")
+	}
+	mer.auth[2][0] ^= 1
+
+	var h int
+	for h = range mer.stacks {
+		if len(mer.stacks[h].stack) > 0 {
+			break
+		}
+	}
+	mer.stacks[h].top().node[3] ^= 1
+	err = mer.Check(-1)
+	ce, ok = err.(*CorruptError)
+	if !ok {
+		t.Fatal("should detect a wrong stack", err)
+	}
+	if ce.Root || len(ce.Auth) != 0 || !reflect.DeepEqual(ce.Stacks, []uint32{uint32(h)}) {
+		t.Error("invalid report", ce)
+	}
+	mer.stacks[h].top().node[3] ^= 1
+
+	mer.priv.root[0] ^= 1
+	err = mer.Check(-1)
+	ce, ok = err.(*CorruptError)
+	if !ok {
+		t.Fatal("should detect a wrong root", err)
+	}
+	if !ce.Root || len(ce.Auth) != 0 || len(ce.Stacks) != 0 {
+		t.Error("invalid report", ce)
+	}
+	mer.priv.root[0] ^= 1
+	t.Log(ce)
+
+	mer.Destroy()
+	if err := mer.Check(-1); err != ErrDestroyed {
+		t.Error("should not check destroyed keys", err)
+	}
+}
+
+func TestCheckEveryLeaf(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for checking states at every leaf.")
+	for _, k := range []byte{0, 2} {
+		mer, err := NewMerkleK(5, k, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 1<<5; i++ {
+			if err := mer.Check(-1); err != nil {
+				t.Fatal(k, i, err)
+			}
+			if _, err := mer.Sign(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := mer.Check(-1); err != nil {
+			t.Error(k, err)
+		}
+	}
+}
+
+func TestCheckMT(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for checking states of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 40; i++ {
+		if _, err := mt.Sign(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mt.Check(-1); err != nil {
+		t.Fatal(err)
+	}
+	mt.merkle[1].auth[4][7] ^= 1
+	err = mt.Check(3)
+	ce, ok := err.(*CorruptError)
+	if !ok {
+		t.Fatal("should detect a wrong auth node", err)
+	}
+	if ce.Layer != 1 || !ce.Root {
+		t.Error("invalid report", ce)
+	}
+}
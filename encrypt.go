@@ -0,0 +1,263 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//Encrypted keys are stored in the envelope below.
+//All integers are big endian, and the header (all fields before the ciphertext)
+//is authenticated as additional data of the AEAD cipher.
+//
+//	magic      4 bytes "XMSE"
+//	version    1 byte  (1)
+//	kind       1 byte  (1:Merkle, 2:PrivKeyMT)
+//	kdf        1 byte  (1:PBKDF2-HMAC-SHA256)
+//	iteration  4 bytes
+//	salt       16 bytes
+//	cipher     1 byte  (1:AES-256-GCM)
+//	nonce      12 bytes
+//	public key 65 bytes
+//	ciphertext of the JSON-marshalled key, followed by 16 bytes tag
+const (
+	encVersion    = 1
+	encKDFPBKDF2  = 1
+	encCipherGCM  = 1
+	encSaltSize   = 16
+	encNonceSize  = 12
+	encHeaderSize = 4 + 1 + 1 + 1 + 4 + encSaltSize + 1 + encNonceSize + 1 + n + n
+
+	encKindMerkle    = 1
+	encKindPrivKeyMT = 2
+
+	//maxKDFIterations limits the work of decrypting a forged envelope
+	//to 4 times of DefaultKDFIterations.
+	maxKDFIterations = 1 << 22
+)
+
+var encMagic = []byte("XMSE")
+
+//DefaultKDFIterations is the number of PBKDF2 iterations used when encrypting keys.
+const DefaultKDFIterations = 1 << 20
+
+//pbkdf2 derives a key of keyLen bytes by PBKDF2-HMAC-SHA256 (RFC 8018).
+func pbkdf2(password, salt []byte, iter uint32, keyLen int) []byte {
+	mac := hmac.New(sha256.New, password)
+	hashLen := mac.Size()
+	nblock := (keyLen + hashLen - 1) / hashLen
+	var buf [4]byte
+	dk := make([]byte, 0, nblock*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= nblock; block++ {
+		mac.Reset()
+		if _, err := mac.Write(salt); err != nil {
+			panic(err)
+		}
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		if _, err := mac.Write(buf[:4]); err != nil {
+			panic(err)
+		}
+		dk = mac.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		for i := uint32(1); i < iter; i++ {
+			mac.Reset()
+			if _, err := mac.Write(u); err != nil {
+				panic(err)
+			}
+			u = mac.Sum(u[:0])
+			for j := range u {
+				t[j] ^= u[j]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
+
+func encrypt(kind byte, pub, plain, pass []byte, iter uint32) ([]byte, error) {
+	if iter == 0 || iter > maxKDFIterations {
+		return nil, errors.New("invalid number of iterations")
+	}
+	if len(pub) != 1+n+n {
+		return nil, errors.New("invalid public key length")
+	}
+	header := make([]byte, encHeaderSize)
+	copy(header, encMagic)
+	header[4] = encVersion
+	header[5] = kind
+	header[6] = encKDFPBKDF2
+	binary.BigEndian.PutUint32(header[7:], iter)
+	salt := header[11 : 11+encSaltSize]
+	header[11+encSaltSize] = encCipherGCM
+	nonce := header[12+encSaltSize : 12+encSaltSize+encNonceSize]
+	copy(header[12+encSaltSize+encNonceSize:], pub)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(pass, salt, iter)
+	if err != nil {
+		return nil, err
+	}
+	dat := aead.Seal(header, nonce, plain, header)
+	wipe(plain)
+	return dat, nil
+}
+
+func newAEAD(pass, salt []byte, iter uint32) (cipher.AEAD, error) {
+	key := pbkdf2(pass, salt, iter, 32)
+	block, err := aes.NewCipher(key)
+	wipe(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//parseEnvelope checks the header of an encrypted key and returns its public key.
+func parseEnvelope(dat []byte, kind byte) ([]byte, error) {
+	if len(dat) < encHeaderSize || !bytes.Equal(dat[:4], encMagic) {
+		return nil, ValidationError("not an encrypted key")
+	}
+	if dat[4] != encVersion {
+		return nil, ValidationError("unsupported version of encrypted key")
+	}
+	if dat[5] != kind {
+		return nil, ValidationError("invalid kind of encrypted key")
+	}
+	if dat[6] != encKDFPBKDF2 || dat[11+encSaltSize] != encCipherGCM {
+		return nil, ValidationError("unsupported KDF or cipher")
+	}
+	return dat[12+encSaltSize+encNonceSize : encHeaderSize], nil
+}
+
+func decrypt(dat, pass []byte, kind byte) ([]byte, []byte, error) {
+	pub, err := parseEnvelope(dat, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	iter := binary.BigEndian.Uint32(dat[7:])
+	if iter == 0 || iter > maxKDFIterations {
+		return nil, nil, errors.New("invalid number of iterations")
+	}
+	salt := dat[11 : 11+encSaltSize]
+	nonce := dat[12+encSaltSize : 12+encSaltSize+encNonceSize]
+	aead, err := newAEAD(pass, salt, iter)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := aead.Open(nil, nonce, dat[encHeaderSize:], dat[:encHeaderSize])
+	if err != nil {
+		return nil, nil, errors.New("invalid passphrase or broken encrypted key")
+	}
+	return pub, plain, nil
+}
+
+//EncryptedPublicKey returns the public key in the header of an encrypted key
+//without decrypting it.
+func EncryptedPublicKey(dat []byte) ([]byte, error) {
+	if len(dat) < 6 {
+		return nil, ValidationError("not an encrypted key")
+	}
+	pub, err := parseEnvelope(dat, dat[5])
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, len(pub))
+	copy(key, pub)
+	return key, nil
+}
+
+//Encrypt returns Merkle encrypted with the passphrase.
+func (m *Merkle) Encrypt(pass []byte) ([]byte, error) {
+	return m.encrypt(pass, DefaultKDFIterations)
+}
+
+func (m *Merkle) encrypt(pass []byte, iter uint32) ([]byte, error) {
+	plain, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(encKindMerkle, m.PublicKey(), plain, pass, iter)
+}
+
+//DecryptMerkle decrypts the encrypted Merkle with the passphrase.
+func DecryptMerkle(dat, pass []byte) (*Merkle, error) {
+	pub, plain, err := decrypt(dat, pass, encKindMerkle)
+	if err != nil {
+		return nil, err
+	}
+	var m Merkle
+	err = json.Unmarshal(plain, &m)
+	wipe(plain)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pub, m.PublicKey()) {
+		m.Destroy()
+		return nil, errors.New("public key does not match")
+	}
+	return &m, nil
+}
+
+//Encrypt returns PrivKeyMT encrypted with the passphrase.
+func (p *PrivKeyMT) Encrypt(pass []byte) ([]byte, error) {
+	return p.encrypt(pass, DefaultKDFIterations)
+}
+
+func (p *PrivKeyMT) encrypt(pass []byte, iter uint32) ([]byte, error) {
+	plain, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(encKindPrivKeyMT, p.PublicKey(), plain, pass, iter)
+}
+
+//DecryptPrivKeyMT decrypts the encrypted PrivKeyMT with the passphrase.
+func DecryptPrivKeyMT(dat, pass []byte) (*PrivKeyMT, error) {
+	pub, plain, err := decrypt(dat, pass, encKindPrivKeyMT)
+	if err != nil {
+		return nil, err
+	}
+	var p PrivKeyMT
+	err = json.Unmarshal(plain, &p)
+	wipe(plain)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pub, p.PublicKey()) {
+		p.Destroy()
+		return nil, errors.New("public key does not match")
+	}
+	return &p, nil
+}
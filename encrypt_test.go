@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	//test vectors from RFC 7914
+	dk := pbkdf2([]byte("passwd"), []byte("salt"), 1, 64)
+	if hex.EncodeToString(dk) != "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783" {
+		t.Error("incorrect pbkdf2", hex.EncodeToString(dk))
+	}
+	dk = pbkdf2([]byte("Password"), []byte("NaCl"), 80000, 64)
+	if hex.EncodeToString(dk) != "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d" {
+		t.Error("incorrect pbkdf2", hex.EncodeToString(dk))
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	seed := generateSeed()
+	mer := NewMerkle(4, seed)
+	pass := []byte("passphrase")
+	msg := []byte("This is a test for encrypting XMSS.")
+	dat, err := mer.encrypt(pass, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(dat, mer.priv.wotsPRF.seed) {
+		t.Error("seed must not be in plaintext")
+	}
+	pub, err := EncryptedPublicKey(dat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, mer.PublicKey()) {
+		t.Error("invalid public key in header")
+	}
+	mer2, err := DecryptMerkle(dat, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := mer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Error("invalid decryption")
+	}
+
+	if _, err := DecryptMerkle(dat, []byte("wrong")); err == nil {
+		t.Error("should not decrypt with wrong passphrase")
+	}
+	for _, i := range []int{5, 8, encHeaderSize - 1, len(dat) - 1} {
+		dat[i] ^= 1
+		if _, err := DecryptMerkle(dat, pass); err == nil {
+			t.Error("should not decrypt tampered data", i)
+		}
+		dat[i] ^= 1
+	}
+	if _, err := DecryptPrivKeyMT(dat, pass); err == nil {
+		t.Error("should not decrypt Merkle as PrivKeyMT")
+	}
+
+	if _, err := mer.encrypt(pass, maxKDFIterations+1); err == nil {
+		t.Error("should not encrypt with too many iterations")
+	}
+	forged := make([]byte, len(dat))
+	copy(forged, dat)
+	binary.BigEndian.PutUint32(forged[7:], maxKDFIterations+1)
+	if _, err := DecryptMerkle(forged, pass); err == nil {
+		t.Error("should not decrypt with too many iterations")
+	}
+
+	plain, err := json.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewMerkle(4, generateSeed())
+	dat, err = encrypt(encKindMerkle, other.PublicKey(), plain, pass, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptMerkle(dat, pass); err == nil {
+		t.Error("should not decrypt with another public key")
+	}
+}
+
+func TestEncryptMT(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass := []byte("passphrase")
+	msg := []byte("This is a test for encrypting XMSS^MT.")
+	dat, err := mer.encrypt(pass, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mer2, err := DecryptPrivKeyMT(dat, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := mer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Error("invalid decryption")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"crypto/hmac"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//Stored states of Merkle and PrivKeyMT carry an epoch and a MAC keyed with the secret seed.
+//The epoch increases whenever the leaf number changes, and the MAC covers the whole state
+//with the epoch, so edited states are rejected when loading. States without MAC are rejected.
+//
+//The MAC key is the WOTS+ seed, which is stored in the same state by Marshal and MarshalBinary.
+//Anyone who can edit such a state can read the key and compute a valid MAC,
+//so the MAC detects tampering only if the seeds are kept apart from the state
+//by State and Seeds (see state.go).
+//
+//The MAC cannot detect that an old (but genuine) state is restored.
+//To detect it, set a Counter, which records the epoch of the last signature outside the state
+//(e.g. in a monotonic counter of a TPM or in a database), by SetCounter after loading.
+
+//Counter is an external monotonic counter of epochs of keys.
+type Counter interface {
+	//Get returns the last recorded epoch of the key with keyID, or 0 if not recorded.
+	Get(keyID []byte) (uint64, error)
+	//Set records epoch of the key with keyID.
+	//It must fail if epoch is not larger than the recorded one.
+	Set(keyID []byte, epoch uint64) error
+}
+
+//ErrRollback is returned when a state is older than the epoch recorded by Counter.
+var ErrRollback = ValidationError("state was rolled back")
+
+//stateMAC returns MAC of body keyed with seed.
+func stateMAC(seed, body []byte) []byte {
+	mac := hmac.New(sha256.New, seed)
+	if _, err := mac.Write([]byte("xmss state")); err != nil {
+		panic(err)
+	}
+	if _, err := mac.Write(body); err != nil {
+		panic(err)
+	}
+	return mac.Sum(nil)
+}
+
+//mac returns MAC of the state of m over its body in binary version v.
+func (m *Merkle) mac(v byte) []byte {
+	e := &encoder{v: v}
+	m.encodeBody(e)
+	return stateMAC(m.priv.wotsPRF.seed, e.b)
+}
+
+//checkMAC checks mac of the state of m, which was made in binary version v.
+func (m *Merkle) checkMAC(mac []byte, v byte) error {
+	if mac == nil {
+		return ValidationError("state has no MAC")
+	}
+	if !hmac.Equal(mac, m.mac(v)) {
+		return ValidationError("state was tampered")
+	}
+	return nil
+}
+
+//Epoch returns the epoch of the state, which increases whenever the leaf number changes.
+func (m *Merkle) Epoch() uint64 {
+	return m.epoch
+}
+
+//SetCounter sets c to m after checking that m is not older than the epoch recorded in c.
+//After this, Sign records the epoch into c before returning a signature.
+//A Merkle returned by Split has no Counter.
+func (m *Merkle) SetCounter(c Counter) error {
+	m.Flush()
+	if err := checkCounter(c, m.KeyID(), m.epoch); err != nil {
+		return err
+	}
+	m.counter = c
+	return nil
+}
+
+//commit records the epoch of m into its Counter.
+func (m *Merkle) commit() error {
+	if m.counter == nil {
+		return nil
+	}
+	return m.counter.Set(m.KeyID(), m.epoch)
+}
+
+func checkCounter(c Counter, id []byte, epoch uint64) error {
+	if c == nil {
+		return nil
+	}
+	e, err := c.Get(id)
+	if err != nil {
+		return err
+	}
+	if epoch < e {
+		return ErrRollback
+	}
+	return nil
+}
+
+//mac returns MAC of the state of p over its body in binary version v.
+func (p *PrivKeyMT) mac(v byte) []byte {
+	e := &encoder{v: v}
+	p.encodeBody(e)
+	return stateMAC(p.merkle[p.d-1].priv.wotsPRF.seed, e.b)
+}
+
+//checkMAC checks mac of the state of p, which was made in binary version v.
+func (p *PrivKeyMT) checkMAC(mac []byte, v byte) error {
+	if mac == nil {
+		return ValidationError("state has no MAC")
+	}
+	if !hmac.Equal(mac, p.mac(v)) {
+		return ValidationError("state was tampered")
+	}
+	return nil
+}
+
+//Epoch returns the epoch of the state, which increases whenever the index changes.
+func (p *PrivKeyMT) Epoch() uint64 {
+	return p.epoch
+}
+
+//SetCounter sets c to p after checking that p is not older than the epoch recorded in c.
+//After this, Sign records the epoch into c before returning a signature.
+//A PrivKeyMT returned by Split has no Counter.
+func (p *PrivKeyMT) SetCounter(c Counter) error {
+	if err := checkCounter(c, p.KeyID(), p.epoch); err != nil {
+		return err
+	}
+	p.counter = c
+	return nil
+}
+
+//commit records the epoch of p into its Counter.
+func (p *PrivKeyMT) commit() error {
+	if p.counter == nil {
+		return nil
+	}
+	return p.counter.Set(p.KeyID(), p.epoch)
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+type memCounter map[string]uint64
+
+func (c memCounter) Get(id []byte) (uint64, error) {
+	return c[string(id)], nil
+}
+
+func (c memCounter) Set(id []byte, epoch uint64) error {
+	if epoch <= c[string(id)] {
+		return errors.New("epoch must increase")
+	}
+	c[string(id)] = epoch
+	return nil
+}
+
+func TestEpoch(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for epochs.")
+	mer := NewMerkle(4, seed)
+	c := memCounter{}
+	if err := mer.SetCounter(c); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := mer.Sign(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mer.Epoch() != 3 || c[string(mer.KeyID())] != 3 {
+		t.Error("invalid epoch", mer.Epoch(), c[string(mer.KeyID())])
+	}
+	old, err := json.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mer.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	dat, err := json.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdat, err := msgpack.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bdat, err := mer.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mer2 Merkle
+	if err := json.Unmarshal(old, &mer2); err != nil {
+		t.Fatal(err)
+	}
+	if err := mer2.SetCounter(c); err != ErrRollback {
+		t.Error("should detect rollback", err)
+	}
+	if err := json.Unmarshal(dat, &mer2); err != nil {
+		t.Fatal(err)
+	}
+	if err := mer2.SetCounter(c); err != nil {
+		t.Error(err)
+	}
+	if err := msgpack.Unmarshal(mdat, &mer2); err != nil {
+		t.Error(err)
+	}
+	if err := mer2.UnmarshalBinary(bdat); err != nil {
+		t.Error(err)
+	}
+
+	//roll Leaf back in the stored state.
+	bad := bytes.Replace(dat, []byte(`"Leaf":4`), []byte(`"Leaf":1`), 1)
+	if bytes.Equal(bad, dat) {
+		t.Fatal("failed to edit the state")
+	}
+	if err := json.Unmarshal(bad, &mer2); err == nil {
+		t.Error("should not load an edited state")
+	}
+	bad = bytes.Replace(dat, []byte(`"Epoch":4`), []byte(`"Epoch":9`), 1)
+	if err := json.Unmarshal(bad, &mer2); err == nil {
+		t.Error("should not load an edited state")
+	}
+	//a state without MAC is rejected.
+	var js map[string]interface{}
+	if err := json.Unmarshal(dat, &js); err != nil {
+		t.Fatal(err)
+	}
+	js["Epoch"] = 0
+	delete(js, "MAC")
+	bad, err = json.Marshal(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(bad, &mer2); err == nil {
+		t.Error("should not load a state without MAC")
+	} else if _, ok := err.(ValidationError); !ok {
+		t.Error("invalid error", err)
+	}
+	bad = append([]byte{}, bdat...)
+	bad[5+4+4+4+8+3] ^= 1
+	if err := mer2.UnmarshalBinary(bad); err == nil {
+		t.Error("should not load an edited state")
+	}
+
+	//version 2 has no suspect flag, and its MAC does not cover it.
+	v2 := append([]byte{2}, bdat[1:len(bdat)-1-n]...)
+	v2 = append(v2, mer.mac(2)...)
+	if err := mer2.UnmarshalBinary(v2); err != nil {
+		t.Fatal(err)
+	}
+	if mer2.Epoch() != 4 || mer2.Suspect() {
+		t.Error("invalid migration", mer2.Epoch(), mer2.Suspect())
+	}
+	v2[len(v2)-n-8] ^= 1
+	if err := mer2.UnmarshalBinary(v2); err == nil {
+		t.Error("should not load an edited state")
+	}
+
+	//version 1 has no epoch and MAC, so that it could be rolled back or edited.
+	v1 := append([]byte{1}, bdat[1:len(bdat)-1-8-n]...)
+	if err := mer2.UnmarshalBinary(v1); err != ValidationError("state has no MAC") {
+		t.Error("should not load a state without MAC", err)
+	}
+
+	c[string(mer.KeyID())] = 100
+	if _, err := mer.Sign(msg); err == nil {
+		t.Error("should not return signatures if counter fails")
+	}
+}
+
+func TestEpochMT(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for epochs of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := memCounter{}
+	if err := mt.SetCounter(c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	old, err := json.Marshal(mt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	if mt.Epoch() != 2 || c[string(mt.KeyID())] != 2 {
+		t.Error("invalid epoch", mt.Epoch())
+	}
+	bdat, err := mt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mt2 PrivKeyMT
+	if err := json.Unmarshal(old, &mt2); err != nil {
+		t.Fatal(err)
+	}
+	if err := mt2.SetCounter(c); err != ErrRollback {
+		t.Error("should detect rollback", err)
+	}
+	bad := bytes.Replace(old, []byte(`"Index":1`), []byte(`"Index":0`), 1)
+	if bytes.Equal(bad, old) {
+		t.Fatal("failed to edit the state")
+	}
+	if err := json.Unmarshal(bad, &mt2); err == nil {
+		t.Error("should not load an edited state")
+	}
+	if err := mt2.UnmarshalBinary(bdat); err != nil {
+		t.Fatal(err)
+	}
+	if err := mt2.SetCounter(c); err != nil {
+		t.Error(err)
+	}
+	var js map[string]interface{}
+	if err := json.Unmarshal(old, &js); err != nil {
+		t.Fatal(err)
+	}
+	js["Epoch"] = 0
+	delete(js, "MAC")
+	bad, err = json.Marshal(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(bad, &mt2); err == nil {
+		t.Error("should not load a state without MAC")
+	} else if _, ok := err.(ValidationError); !ok {
+		t.Error("invalid error", err)
+	}
+	bdat[5+4+4+7] ^= 1
+	if err := mt2.UnmarshalBinary(bdat); err == nil {
+		t.Error("should not load an edited state")
+	}
+}
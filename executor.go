@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+//Executor runs tasks concurrently for key generation, signing and verification.
+type Executor interface {
+	//Run calls f(i) for all i in [0, n) and returns after all calls finish.
+	//The caller may run some or all of the calls by itself.
+	Run(n int, f func(i int))
+	//Concurrency returns the max number of calls which run at the same time.
+	Concurrency() int
+}
+
+//Pool is an Executor with a fixed number of persistent workers.
+//Tasks are handed to idle workers only, and the caller runs the rest by itself,
+//so the number of running goroutines is bounded even if many keys use the same Pool
+//and Run is called from tasks.
+type Pool struct {
+	tasks chan func()
+	size  int
+}
+
+//NewPool returns Pool which runs at most size calls at the same time,
+//including the caller of Run. It starts size-1 workers.
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	p := &Pool{
+		tasks: make(chan func()),
+		size:  size,
+	}
+	for i := 0; i < size-1; i++ {
+		go func() {
+			for f := range p.tasks {
+				f()
+			}
+		}()
+	}
+	return p
+}
+
+//Concurrency returns the max number of calls which run at the same time.
+func (p *Pool) Concurrency() int {
+	return p.size
+}
+
+//Run calls f(i) for all i in [0, n) with idle workers and the caller.
+func (p *Pool) Run(n int, f func(i int)) {
+	if p.size == 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	var next int64
+	work := func() {
+		for {
+			i := atomic.AddInt64(&next, 1) - 1
+			if i >= int64(n) {
+				return
+			}
+			f(int(i))
+		}
+	}
+	var wg sync.WaitGroup
+	task := func() {
+		work()
+		wg.Done()
+	}
+loop:
+	for i := 0; i < p.size-1 && i < n-1; i++ {
+		wg.Add(1)
+		select {
+		case p.tasks <- task:
+		default:
+			wg.Done()
+			break loop
+		}
+	}
+	work()
+	wg.Wait()
+}
+
+//Close stops workers of the pool. The pool must not be used after calling this.
+func (p *Pool) Close() {
+	close(p.tasks)
+}
+
+var (
+	defaultPool *Pool
+	defaultOnce sync.Once
+)
+
+//DefaultExecutor returns the Executor shared by keys which don't have their own,
+//a Pool whose size is GOMAXPROCS at the first call.
+func DefaultExecutor() Executor {
+	defaultOnce.Do(func() {
+		defaultPool = NewPool(runtime.GOMAXPROCS(-1))
+	})
+	return defaultPool
+}
+
+func executorOr(e Executor) Executor {
+	if e == nil {
+		return DefaultExecutor()
+	}
+	return e
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	p := NewPool(4)
+	defer p.Close()
+	if p.Concurrency() != 4 {
+		t.Error("invalid concurrency", p.Concurrency())
+	}
+	var running, max int32
+	called := make([]int32, 100)
+	p.Run(len(called), func(i int) {
+		r := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if r <= m || atomic.CompareAndSwapInt32(&max, m, r) {
+				break
+			}
+		}
+		//nested Run must not deadlock.
+		p.Run(3, func(int) {})
+		atomic.AddInt32(&called[i], 1)
+		atomic.AddInt32(&running, -1)
+	})
+	for i, c := range called {
+		if c != 1 {
+			t.Error("invalid number of calls", i, c)
+		}
+	}
+	if max > 4 {
+		t.Error("too many calls at the same time", max)
+	}
+}
+
+func TestExecutor(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for executors.")
+	p := NewPool(1)
+	defer p.Close()
+
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	mer1 := newMerkle(6, wotsSeed, msgSeed, pubSeed, 0, 0, p)
+	mer2 := NewMerkle(6, seed)
+	mer2.SetExecutor(p)
+	sig1, err := mer1.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := mer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig1, sig2) {
+		t.Error("signatures differ")
+	}
+	if !VerifyWith(p, sig1, msg, mer1.PublicKey()) {
+		t.Error("XMSS sig is incorrect")
+	}
+
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt.SetExecutor(p)
+	sig, err := mt.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range mt.merkle {
+		if m.priv.exec != p {
+			t.Error("executor is not set")
+		}
+	}
+	if !VerifyMTWith(p, sig, msg, mt.PublicKey()) {
+		t.Error("XMSS^MT sig is incorrect")
+	}
+}
+
+func TestSubtreeScheduling(t *testing.T) {
+	for _, c := range []struct {
+		h    uint32
+		ncpu int
+		lo   uint32
+	}{
+		{10, 1, 10},
+		{10, 2, 6},
+		{10, 6, 4},
+		{10, 12, 3},
+		{10, 24, 2},
+		{4, 24, 0},
+	} {
+		if lo := subtreeHeight(c.h, c.ncpu); lo != c.lo {
+			t.Error("invalid height of subtrees", c.h, c.ncpu, lo)
+		}
+	}
+
+	seed := generateSeed()
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	for _, k := range []uint32{0, 2} {
+		mer := newMerkleK(7, k, wotsSeed, msgSeed, pubSeed, 0, 0, NewPool(1))
+		dat, err := json.Marshal(mer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, size := range []int{3, 6, 12, 24} {
+			p := NewPool(size)
+			pmer := newMerkleK(7, k, wotsSeed, msgSeed, pubSeed, 0, 0, p)
+			pdat, err := json.Marshal(pmer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dat, pdat) {
+				t.Error("states differ", k, size)
+			}
+			for i := 0; i < 1<<5; i++ {
+				pmer.Traverse()
+				mer.Traverse()
+			}
+			p.Close()
+			if !bytes.Equal(pmer.auth[3], mer.auth[3]) {
+				t.Error("auth paths differ", k, size)
+			}
+			mer = newMerkleK(7, k, wotsSeed, msgSeed, pubSeed, 0, 0, NewPool(1))
+		}
+	}
+}
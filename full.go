@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "errors"
+
+//A Merkle whose k equals to its height retains all nodes in the tree,
+//so that the auth path of any leaf is available without traversing.
+//Leaves in such a Merkle can be used out of order by SignAt,
+//and used leaves above LeafNo() are recorded in a bitmap.
+
+//maxFullHeight is the max height of a tree which retains all nodes.
+const maxFullHeight = 16
+
+//NewMerkleFull makes Merkle struct from height and private seed,
+//retaining all nodes in the tree to sign at any leaf by SignAt.
+//It is same as NewMerkleK(h, h, seed), but h must not be larger than 16.
+//The Merkle holds 2^(h+1)-2 nodes of 32 bytes, which take about 70 bytes each
+//in memory with their headers (about 9 MB for h=16),
+//and a bitmap of 2^h/8 bytes (8 KB for h=16).
+func NewMerkleFull(h byte, seed []byte) (*Merkle, error) {
+	if h > maxFullHeight {
+		return nil, errors.New("height must not be larger than 16 to retain all nodes")
+	}
+	return NewMerkleK(h, h, seed)
+}
+
+//full returns true if m retains all nodes.
+func (m *Merkle) full() bool {
+	return m.k == m.Height
+}
+
+//usedSize returns the size of the bitmap of used leaves for m.
+func (m *Merkle) usedSize() int {
+	if !m.full() {
+		return 0
+	}
+	return (1<<m.Height + 7) / 8
+}
+
+//Used returns true if the leaf at index was used or skipped.
+func (m *Merkle) Used(index uint64) bool {
+	if index < uint64(m.Leaf) {
+		return true
+	}
+	if index >= 1<<m.Height || len(m.used) == 0 {
+		return false
+	}
+	return m.used[index>>3]&(1<<(index&7)) != 0
+}
+
+//authAt returns the auth path of leaf from retained nodes.
+func (m *Merkle) authAt(leaf uint32) [][]byte {
+	auth := make([][]byte, m.Height)
+	for h := uint32(0); h < m.Height; h++ {
+		auth[h] = m.retained(h, (leaf>>h)^1)
+	}
+	return auth
+}
+
+//SignAt signs msg by XMSS with the leaf at index, which must not be used yet.
+//It is available only if m retains all nodes (see NewMerkleFull).
+//The state must be saved after calling this as Sign does.
+func (m *Merkle) SignAt(index uint64, msg []byte) ([]byte, error) {
+	m.Flush()
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
+	if m.suspect {
+		return nil, ErrSuspect
+	}
+	if err := checkSelfTest(); err != nil {
+		return nil, err
+	}
+	if !m.full() {
+		return nil, errors.New("SignAt needs a Merkle which retains all nodes")
+	}
+	if err := m.checkRange(); err != nil {
+		return nil, err
+	}
+	if index < m.start || index >= m.end {
+		return nil, errors.New("leaf index is out of range")
+	}
+	if m.Used(index) {
+		return nil, errors.New("leaf is already used")
+	}
+	if index == uint64(m.Leaf) {
+		return m.Sign(msg)
+	}
+	leaf := uint32(index)
+	m.used[leaf>>3] |= 1 << (leaf & 7)
+	m.epoch++
+	sig := m.signBytes(leaf, msg, m.authAt(leaf))
+	if err := m.commit(); err != nil {
+		return nil, err
+	}
+	if err := m.checkSig(sig, msg); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+//skipUsed moves m to the first unused leaf if the current one was used by SignAt.
+func (m *Merkle) skipUsed() {
+	next := uint64(m.Leaf)
+	for next < m.end && m.Used(next) {
+		next++
+	}
+	if next != uint64(m.Leaf) {
+		//never fails because next is in the range.
+		m.SetLeafNo(next)
+	}
+}
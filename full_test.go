@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSignAt(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for signing at any leaf.")
+	mer := NewMerkle(5, seed)
+	if _, err := mer.SignAt(3, msg); err == nil {
+		t.Error("should not sign at any leaf without all nodes")
+	}
+	fmer, err := NewMerkleFull(5, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := make([][]byte, 1<<5)
+	for i := range sigs {
+		sigs[i], err = mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, i := range []uint64{7, 3, 31, 4, 0, 1, 2, 5, 9} {
+		sig, err := fmer.SignAt(i, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sigs[i]) {
+			t.Error("signatures differ", i)
+		}
+		if _, err := fmer.SignAt(i, msg); err == nil {
+			t.Error("should not sign at a used leaf", i)
+		}
+	}
+	if fmer.LeafNo() != 6 {
+		t.Error("used leaves must be skipped", fmer.LeafNo())
+	}
+	if !fmer.Used(9) || fmer.Used(8) {
+		t.Error("invalid used leaves")
+	}
+
+	dat, err := json.Marshal(fmer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fmer2 Merkle
+	if err := json.Unmarshal(dat, &fmer2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fmer2.SignAt(31, msg); err == nil {
+		t.Error("should not sign at a used leaf after unmarshaling")
+	}
+	child, err := fmer2.Split(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.SignAt(7, msg); err == nil {
+		t.Error("should not sign at a used leaf after splitting")
+	}
+	if _, err := child.SignAt(10, msg); err == nil {
+		t.Error("should not sign out of range")
+	}
+	for i := uint64(6); i < 1<<5-1; i++ {
+		if i == 7 || i == 9 {
+			continue
+		}
+		sig, err := fmer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sigs[i]) {
+			t.Error("signatures differ", i)
+		}
+	}
+	if _, err := fmer.Sign(msg); err == nil {
+		t.Error("should not sign with exhausted key")
+	}
+}
+
+func TestNewMerkleFullHeight(t *testing.T) {
+	if _, err := NewMerkleFull(maxFullHeight+1, generateSeed()); err == nil {
+		t.Error("should not retain all nodes of a tree higher than 16")
+	}
+}
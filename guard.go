@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "errors"
+
+//With the guard on, Sign verifies each signature against the root of the key itself
+//before returning it, as a pairwise consistency test.
+//A signature can be invalid if the state in memory is corrupt (e.g. by a bit flip or
+//a bad import). The used leaf is never used again in that case, but the signature is
+//not returned, and the key refuses to sign until Check with all nodes passes.
+
+//ErrSuspect is returned when a signature failed verification by the guard,
+//or when signing with a key whose state is suspect.
+var ErrSuspect = errors.New("state is suspect because a signature failed self-verification")
+
+//SetGuard sets whether Sign and SignAt verify signatures before returning them.
+//It costs about one verification per signature.
+func (m *Merkle) SetGuard(on bool) {
+	m.guard = on
+}
+
+//Suspect returns true if a signature by m failed verification by the guard.
+func (m *Merkle) Suspect() bool {
+	return m.suspect
+}
+
+//checkSig verifies sig by m if the guard is on, and marks m suspect if invalid.
+func (m *Merkle) checkSig(sig, msg []byte) error {
+	if !m.guard {
+		return nil
+	}
+	pk := PublicKey{
+		Height: byte(m.Height),
+		Root:   m.priv.root,
+		Seed:   m.priv.pubPRF.seed,
+	}
+	if !verifyPK(m.priv.executor(), sig, msg, &pk) {
+		m.suspect = true
+		return ErrSuspect
+	}
+	return nil
+}
+
+//SetGuard sets whether Sign verifies signatures before returning them.
+//It costs about one verification per signature.
+func (p *PrivKeyMT) SetGuard(on bool) {
+	p.guard = on
+}
+
+//Suspect returns true if a signature by p failed verification by the guard.
+func (p *PrivKeyMT) Suspect() bool {
+	return p.suspect
+}
+
+//checkSig verifies sig by p if the guard is on, and marks p suspect if invalid.
+//Cached signatures of upper layers are dropped if invalid, because they may be the cause.
+func (p *PrivKeyMT) checkSig(sig, msg []byte) error {
+	if !p.guard {
+		return nil
+	}
+	if !VerifyMTWith(p.merkle[p.d-1].priv.executor(), sig, msg, p.PublicKey()) {
+		p.suspect = true
+		p.sigs = nil
+		return ErrSuspect
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+func TestGuard(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for the guard.")
+	mer := NewMerkle(6, seed)
+	mer.SetGuard(true)
+	for i := 0; i < 3; i++ {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(sig, msg, mer.PublicKey()) {
+			t.Fatal("signature is invalid")
+		}
+	}
+
+	mer.auth[5][0] ^= 1
+	if _, err := mer.Sign(msg); err != ErrSuspect {
+		t.Fatal("should not return an invalid signature", err)
+	}
+	if !mer.Suspect() || mer.LeafNo() != 4 {
+		t.Error("the leaf must be burned and the state must be suspect", mer.LeafNo())
+	}
+	if _, err := mer.Sign(msg); err != ErrSuspect {
+		t.Error("should not sign with a suspect state", err)
+	}
+	if err := mer.Check(-1); err == nil {
+		t.Error("should detect the wrong auth node")
+	}
+	mer.auth[5][0] ^= 1
+	if err := mer.Check(-1); err != nil {
+		t.Fatal(err)
+	}
+	if mer.Suspect() {
+		t.Error("should not be suspect after checking all nodes")
+	}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(sig, msg, mer.PublicKey()) {
+		t.Error("signature is invalid")
+	}
+
+	mer.SetGuard(false)
+	mer.auth[5][0] ^= 1
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify(sig, msg, mer.PublicKey()) {
+		t.Error("signature should be invalid without the guard")
+	}
+}
+
+func TestGuardMT(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for the guard of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt.SetGuard(true)
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	mt.merkle[2].auth[4][0] ^= 1
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal("cached signatures should be used", err)
+	}
+	mt.sigs[1].body[0] ^= 1
+	if _, err := mt.Sign(msg); err != ErrSuspect {
+		t.Fatal("should not return an invalid signature", err)
+	}
+	if !mt.Suspect() || mt.LeafNo() != 3 {
+		t.Error("the index must be burned and the state must be suspect", mt.LeafNo())
+	}
+	if _, err := mt.Sign(msg); err != ErrSuspect {
+		t.Error("should not sign with a suspect state", err)
+	}
+	mt.merkle[2].auth[4][0] ^= 1
+	if err := mt.Check(-1); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mt.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyMT(sig, msg, mt.PublicKey()) {
+		t.Error("signature is invalid")
+	}
+}
+
+func TestGuardPersist(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for storing suspect states.")
+	mer := NewMerkle(4, seed)
+	mer.SetGuard(true)
+	mer.auth[3][0] ^= 1
+	if _, err := mer.Sign(msg); err != ErrSuspect {
+		t.Fatal("should not return an invalid signature", err)
+	}
+	dat, err := json.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdat, err := msgpack.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bdat, err := mer.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := mer.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := mer.Seeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mers := make([]*Merkle, 4)
+	for i := range mers {
+		mers[i] = &Merkle{}
+	}
+	if err := json.Unmarshal(dat, mers[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := msgpack.Unmarshal(mdat, mers[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := mers[2].UnmarshalBinary(bdat); err != nil {
+		t.Fatal(err)
+	}
+	if mers[3], err = NewMerkleFromState(st, sd); err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range mers {
+		if !m.Suspect() {
+			t.Error("suspect flag is lost", i)
+		}
+		if _, err := m.Sign(msg); err != ErrSuspect {
+			t.Error("should not sign with a restored suspect state", i, err)
+		}
+	}
+	bad := bytes.Replace(dat, []byte(`"Suspect":true`), []byte(`"Suspect":false`), 1)
+	if bytes.Equal(bad, dat) {
+		t.Fatal("failed to edit the state")
+	}
+	if err := json.Unmarshal(bad, &Merkle{}); err == nil {
+		t.Error("should not load a state whose flag is cleared")
+	}
+	bad = append([]byte{}, bdat...)
+	bad[len(bad)-n-1] = 0
+	if err := (&Merkle{}).UnmarshalBinary(bad); err == nil {
+		t.Error("should not load a state whose flag is cleared")
+	}
+}
+
+func TestGuardPersistMT(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for storing suspect states of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt.SetGuard(true)
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	mt.merkle[0].auth[2][0] ^= 1
+	if _, err := mt.Sign(msg); err != ErrSuspect {
+		t.Fatal("should not return an invalid signature", err)
+	}
+	dat, err := json.Marshal(mt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bdat, err := mt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := mt.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := mt.Seeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mts := make([]*PrivKeyMT, 3)
+	mts[0], mts[1] = &PrivKeyMT{}, &PrivKeyMT{}
+	if err := json.Unmarshal(dat, mts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := mts[1].UnmarshalBinary(bdat); err != nil {
+		t.Fatal(err)
+	}
+	if mts[2], err = NewPrivKeyMTFromState(st, sd); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range mts {
+		if !p.Suspect() {
+			t.Error("suspect flag is lost", i)
+		}
+		if _, err := p.Sign(msg); err != ErrSuspect {
+			t.Error("should not sign with a restored suspect state", i, err)
+		}
+	}
+	bad := bytes.Replace(dat, []byte(`"Suspect":true`), []byte(`"Suspect":false`), 1)
+	if bytes.Equal(bad, dat) {
+		t.Fatal("failed to edit the state")
+	}
+	if err := json.Unmarshal(bad, &PrivKeyMT{}); err == nil {
+		t.Error("should not load a state whose flag is cleared")
+	}
+}
@@ -28,7 +28,17 @@ import (
 )
 
 var (
-	zero64 = make([]byte, 64)
+	zero64     = make([]byte, 64)
+	sha256Init = []uint32{
+		sha256.Init0,
+		sha256.Init1,
+		sha256.Init2,
+		sha256.Init3,
+		sha256.Init4,
+		sha256.Init5,
+		sha256.Init6,
+		sha256.Init7,
+	}
 )
 
 const (
@@ -67,53 +77,16 @@ func hashMsg(key, m []byte) []byte {
 
 //key:32bytes, m:32bytes
 func hashF(key, m, out []byte) {
-	stat := []uint32{
-		sha256.Init0,
-		sha256.Init1,
-		sha256.Init2,
-		sha256.Init3,
-		sha256.Init4,
-		sha256.Init5,
-		sha256.Init6,
-		sha256.Init7,
-	}
-	buf := make([]byte, 64)
-	copy(buf[32:], key)
-	sha256.Block(stat, buf)
-	copy(buf, m)
-	copy(buf[32:], zero64)
-	buf[32] = 0x80
-	buf[62] = 0x03
-	// buf[63] = 0x00
-	sha256.Block(stat, buf)
-	sha256.Int2Bytes(stat, out)
+	h := getHasher()
+	h.hashF(key, m, out)
+	putHasher(h)
 }
 
 //key:32bytes, m:64bytes
 func hashH(key, m1, m2, out []byte) {
-	stat := []uint32{
-		sha256.Init0,
-		sha256.Init1,
-		sha256.Init2,
-		sha256.Init3,
-		sha256.Init4,
-		sha256.Init5,
-		sha256.Init6,
-		sha256.Init7,
-	}
-	buf := make([]byte, 64)
-	buf[31] = 0x1
-	copy(buf[32:], key)
-	sha256.Block(stat, buf)
-	copy(buf, m1)
-	copy(buf[32:], m2)
-	sha256.Block(stat, buf)
-	copy(buf, zero64)
-	buf[0] = 0x80
-	buf[62] = 0x04
-	// buf[63] = 0x00
-	sha256.Block(stat, buf)
-	sha256.Int2Bytes(stat, out)
+	h := getHasher()
+	h.hashH(key, m1, m2, out)
+	putHasher(h)
 }
 
 //prf is for getting value from peudo random function.
@@ -123,54 +96,60 @@ type prf struct {
 }
 
 //newPRF returns PRF.
-//seed must be 32bytes.
+//seed must be 32bytes, and is copied into PRF.
 func newPRF(seed []byte) *prf {
+	p := &prf{
+		seed:   make([]byte, 32),
+		block1: make([]uint32, 8),
+	}
 	if seed == nil {
-		seed = make([]byte, 32)
-		if _, err := rand.Read(seed); err != nil {
+		if _, err := rand.Read(p.seed); err != nil {
 			panic(err)
 		}
+		seed = p.seed
 	}
-	p := &prf{
-		seed: seed,
-	}
-	p.block1 = []uint32{
-		sha256.Init0,
-		sha256.Init1,
-		sha256.Init2,
-		sha256.Init3,
-		sha256.Init4,
-		sha256.Init5,
-		sha256.Init6,
-		sha256.Init7,
-	}
-	buf := make([]byte, 64)
+	h := getHasher()
+	p.init(seed, h.buf)
+	putHasher(h)
+	return p
+}
+
+//init sets seed to p, whose seed and block1 are allocated already,
+//using buf (64 bytes) as scratch.
+func (p *prf) init(seed, buf []byte) {
+	copy(p.seed, seed)
+	copy(p.block1, sha256Init)
+	copy(buf, zero64)
 	buf[31] = 0x3
-	copy(buf[32:], seed)
+	copy(buf[32:], p.seed)
 	sha256.Block(p.block1, buf)
-	return p
+	wipe(buf)
 }
 
-//m:32bytes
-func (p *prf) finish(buf, out []byte) {
-	buf[32] = 0x80
-	buf[62] = 0x03
-	// buf[63] = 0x00
-	stat := make([]uint32, 8)
-	copy(stat, p.block1)
-	sha256.Block(stat, buf)
-	sha256.Int2Bytes(stat, out)
+//destroy wipes the seed and the midstate of PRF.
+func (p *prf) destroy() {
+	wipe(p.seed)
+	for i := range p.block1 {
+		p.block1[i] = 0
+	}
+}
+
+//wipe fills b with zeros to erase secrets in it.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
 
 //m:32bytes
 func (p *prf) sum(m, out []byte) {
-	buf := make([]byte, 64)
-	copy(buf, m)
-	p.finish(buf, out)
+	h := getHasher()
+	h.prf(p, m, out)
+	putHasher(h)
 }
 
 func (p *prf) sumInt(m uint32, out []byte) {
-	buf := make([]byte, 64)
-	binary.BigEndian.PutUint32(buf[28:], m)
-	p.finish(buf, out)
+	h := getHasher()
+	h.prfInt(p, m, out)
+	putHasher(h)
 }
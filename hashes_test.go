@@ -129,3 +129,23 @@ func TestHashes(t *testing.T) {
 	}
 
 }
+
+func TestHasherHashMsg(t *testing.T) {
+	key := make([]byte, 96)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	h := getHasher()
+	defer putHasher(h)
+	out := make([]byte, 32)
+	for _, l := range []int{0, 1, 55, 56, 63, 64, 65, 119, 120, 128, 1000} {
+		m := make([]byte, l)
+		if _, err := rand.Read(m); err != nil {
+			t.Fatal(err)
+		}
+		h.hashMsg(key, m, out)
+		if !bytes.Equal(out, hashMsg(key, m)) {
+			t.Error("incorrect hashMsg for length", l)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//Known-answer vectors in testdata/kat are encoded as in RFC 8391 and the XMSS reference
+//implementation: public keys are OID || root || SEED, and indices in XMSS^MT signatures
+//are ceil(h/8) bytes, while this package uses a 1-byte header and 8-byte indices.
+//Seeds are SK_SEED, SK_PRF and SEED in the RFC.
+//
+//The vectors are made outside this package by testdata/kat/gen (see README.md),
+//so that this test doesn't compare the package with itself.
+//Trees with height 20 take long to generate, so vectors of such parameter sets have
+//indices 0 and 1 only, and their keys are generated and compared only with -kat.all
+//(and keys with trees of height 16 are skipped with -short).
+//Signatures of all sets are verified always.
+
+var katAll = flag.Bool("kat.all", false, "generate keys and sign for KAT vectors of all parameter sets")
+
+type katParam struct {
+	name string
+	oid  uint32
+	mt   bool
+	h    uint32
+	d    uint32
+}
+
+//katParams are the parameter sets with SHA2 and n=32 in RFC 8391.
+var katParams = []katParam{
+	{"XMSS-SHA2_10_256", 0x01, false, 10, 1},
+	{"XMSS-SHA2_16_256", 0x02, false, 16, 1},
+	{"XMSS-SHA2_20_256", 0x03, false, 20, 1},
+	{"XMSSMT-SHA2_20/2_256", 0x01, true, 20, 2},
+	{"XMSSMT-SHA2_20/4_256", 0x02, true, 20, 4},
+	{"XMSSMT-SHA2_40/2_256", 0x03, true, 40, 2},
+	{"XMSSMT-SHA2_40/4_256", 0x04, true, 40, 4},
+	{"XMSSMT-SHA2_40/8_256", 0x05, true, 40, 8},
+	{"XMSSMT-SHA2_60/3_256", 0x06, true, 60, 3},
+	{"XMSSMT-SHA2_60/6_256", 0x07, true, 60, 6},
+	{"XMSSMT-SHA2_60/12_256", 0x08, true, 60, 12},
+}
+
+type katFile struct {
+	Name      string
+	OID       uint32
+	MT        bool
+	H         uint32
+	D         uint32
+	SKSeed    string
+	SKPRF     string
+	PubSeed   string
+	PublicKey string
+	Vectors   []*katVector
+}
+
+type katVector struct {
+	Index     uint64
+	Message   string
+	Signature string
+}
+
+//katSigner is Merkle or PrivKeyMT.
+type katSigner interface {
+	PublicKey() []byte
+	LeafNo() uint64
+	SetLeafNo(uint64) error
+	Sign([]byte) ([]byte, error)
+}
+
+func (p *katParam) path() string {
+	return filepath.Join("testdata", "kat", strings.Replace(p.name, "/", "_", -1)+".json")
+}
+
+//slow returns true if generating keys of p takes long.
+func (p *katParam) slow() bool {
+	return p.h/p.d >= 20
+}
+
+//signer returns the key of p made from seeds.
+func (p *katParam) signer(wotsSeed, msgSeed, pubSeed []byte) katSigner {
+	if p.mt {
+		return newPrivKeyMT(p.h, p.d, wotsSeed, msgSeed, pubSeed)
+	}
+	return newMerkle(p.h, wotsSeed, msgSeed, pubSeed, 0, 0, nil)
+}
+
+//publicKey converts the public key in RFC 8391 into the one in this package.
+func (p *katParam) publicKey(r []byte) []byte {
+	pk := make([]byte, 1+2*n)
+	pk[0] = byte(p.h)
+	if p.mt {
+		pk[0], _ = PublickeyMTHeader(p.h, p.d)
+	}
+	copy(pk[1:], r[4:])
+	return pk
+}
+
+//rfcSig converts the signature in this package into the one in RFC 8391.
+func (p *katParam) rfcSig(sig []byte) []byte {
+	if !p.mt {
+		return sig
+	}
+	return sig[8-(p.h+7)/8:]
+}
+
+//sig converts the signature in RFC 8391 into the one in this package.
+func (p *katParam) sig(r []byte) []byte {
+	if !p.mt {
+		return r
+	}
+	return append(make([]byte, 8-(p.h+7)/8), r...)
+}
+
+func (p *katParam) read() (*katFile, error) {
+	dat, err := ioutil.ReadFile(p.path())
+	if err != nil {
+		return nil, err
+	}
+	var f katFile
+	if err := json.Unmarshal(dat, &f); err != nil {
+		return nil, err
+	}
+	if f.Name != p.name || f.OID != p.oid || f.MT != p.mt || f.H != p.h || f.D != p.d {
+		return nil, fmt.Errorf("invalid parameters in %s", p.path())
+	}
+	return &f, nil
+}
+
+func katDecode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestKAT(t *testing.T) {
+	for _, p := range katParams {
+		f, err := p.read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk := p.publicKey(katDecode(t, f.PublicKey))
+		verify := Verify
+		if p.mt {
+			verify = VerifyMT
+		}
+		for _, v := range f.Vectors {
+			msg := katDecode(t, v.Message)
+			sig := p.sig(katDecode(t, v.Signature))
+			if !verify(sig, msg, pk) {
+				t.Error(p.name, v.Index, "signature is invalid")
+			}
+			if verify(sig, append(msg, 0), pk) {
+				t.Error(p.name, v.Index, "signature should be invalid for another message")
+			}
+		}
+
+		if (p.slow() && !*katAll) || (p.h/p.d >= 16 && testing.Short()) {
+			t.Log(p.name, "skipped signing")
+			continue
+		}
+		s := p.signer(katDecode(t, f.SKSeed), katDecode(t, f.SKPRF), katDecode(t, f.PubSeed))
+		if !bytes.Equal(s.PublicKey(), pk) {
+			t.Error(p.name, "public key is incorrect")
+		}
+		for _, v := range f.Vectors {
+			if s.LeafNo() != v.Index {
+				if err := s.SetLeafNo(v.Index); err != nil {
+					t.Fatal(p.name, err)
+				}
+			}
+			sig, err := s.Sign(katDecode(t, v.Message))
+			if err != nil {
+				t.Fatal(p.name, err)
+			}
+			if hex.EncodeToString(p.rfcSig(sig)) != v.Signature {
+				t.Error(p.name, v.Index, "signature is incorrect")
+			}
+		}
+	}
+}
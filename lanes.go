@@ -0,0 +1,243 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//lanes is the max number of hashes computed in lockstep.
+const lanes = 8
+
+//blockLanes compresses blocks[i] into stats[i] for all lanes.
+//All hashes of F, H and PRF in WOTS+ chains and L-trees are given through it.
+//It is blockLanesArch, which compresses blocks of all lanes at once by multi-buffer (SIMD) code
+//for the architecture (AVX2 on amd64 in lanes_amd64.s), or by blockLanesGeneric if not available
+//(or with build tag noasm).
+var blockLanes = blockLanesArch
+
+//blockLanesGeneric compresses blocks[i] into stats[i] one by one.
+func blockLanesGeneric(stats [][]uint32, blocks [][]byte) {
+	for i := range stats {
+		sha256.Block(stats[i], blocks[i])
+	}
+}
+
+//hashLanes holds buffers to compute F, H and PRF for up to lanes inputs in lockstep.
+type hashLanes struct {
+	stat  [lanes][8]uint32
+	block [lanes][64]byte
+	key   [lanes][32]byte
+	bm0   [lanes][32]byte
+	bm1   [lanes][32]byte
+	ab    [lanes][32]byte
+	//views of arrays above.
+	stats  [][]uint32
+	blocks [][]byte
+	keys   [][]byte
+	bm0s   [][]byte
+	bm1s   [][]byte
+	as     [][]byte
+	//lefts, rights and outs are views of arguments.
+	lefts  [][]byte
+	rights [][]byte
+	outs   [][]byte
+}
+
+func newHashLanes() *hashLanes {
+	l := &hashLanes{
+		stats:  make([][]uint32, lanes),
+		blocks: make([][]byte, lanes),
+		keys:   make([][]byte, lanes),
+		bm0s:   make([][]byte, lanes),
+		bm1s:   make([][]byte, lanes),
+		as:     make([][]byte, lanes),
+		lefts:  make([][]byte, lanes),
+		rights: make([][]byte, lanes),
+		outs:   make([][]byte, lanes),
+	}
+	for i := 0; i < lanes; i++ {
+		l.stats[i] = l.stat[i][:]
+		l.blocks[i] = l.block[i][:]
+		l.keys[i] = l.key[i][:]
+		l.bm0s[i] = l.bm0[i][:]
+		l.bm1s[i] = l.bm1[i][:]
+		l.as[i] = l.ab[i][:]
+	}
+	return l
+}
+
+//wipe erases intermediate values, which may be secrets.
+func (l *hashLanes) wipe() {
+	for i := range l.stat {
+		for j := range l.stat[i] {
+			l.stat[i][j] = 0
+		}
+		wipe(l.block[i][:])
+		wipe(l.key[i][:])
+		wipe(l.bm0[i][:])
+		wipe(l.bm1[i][:])
+		l.lefts[i] = nil
+		l.rights[i] = nil
+		l.outs[i] = nil
+	}
+}
+
+func (l *hashLanes) init(nl int) {
+	for i := 0; i < nl; i++ {
+		copy(l.stats[i], sha256Init)
+	}
+}
+
+//prf computes p.sum(ms[i], outs[i]) for all lanes.
+func (l *hashLanes) prf(p *prf, ms [][]byte, outs [][]byte) {
+	nl := len(ms)
+	for i := 0; i < nl; i++ {
+		copy(l.stats[i], p.block1)
+		b := l.blocks[i]
+		copy(b, ms[i][:32])
+		copy(b[32:], zero64)
+		b[32] = 0x80
+		b[62] = 0x03
+	}
+	blockLanes(l.stats[:nl], l.blocks[:nl])
+	for i := 0; i < nl; i++ {
+		sha256.Int2Bytes(l.stats[i], outs[i])
+	}
+}
+
+//hashF computes hashF(keys[i], ms[i], outs[i]) for all lanes.
+func (l *hashLanes) hashF(keys, ms, outs [][]byte) {
+	nl := len(keys)
+	l.init(nl)
+	for i := 0; i < nl; i++ {
+		b := l.blocks[i]
+		copy(b, zero64[:32])
+		copy(b[32:], keys[i])
+	}
+	blockLanes(l.stats[:nl], l.blocks[:nl])
+	for i := 0; i < nl; i++ {
+		b := l.blocks[i]
+		copy(b, ms[i])
+		copy(b[32:], zero64)
+		b[32] = 0x80
+		b[62] = 0x03
+	}
+	blockLanes(l.stats[:nl], l.blocks[:nl])
+	for i := 0; i < nl; i++ {
+		sha256.Int2Bytes(l.stats[i], outs[i])
+	}
+}
+
+//hashH computes hashH(keys[i], m1s[i], m2s[i], outs[i]) for all lanes.
+func (l *hashLanes) hashH(keys, m1s, m2s, outs [][]byte) {
+	nl := len(keys)
+	l.init(nl)
+	for i := 0; i < nl; i++ {
+		b := l.blocks[i]
+		copy(b, zero64[:32])
+		b[31] = 0x1
+		copy(b[32:], keys[i])
+	}
+	blockLanes(l.stats[:nl], l.blocks[:nl])
+	for i := 0; i < nl; i++ {
+		b := l.blocks[i]
+		copy(b, m1s[i])
+		copy(b[32:], m2s[i])
+	}
+	blockLanes(l.stats[:nl], l.blocks[:nl])
+	for i := 0; i < nl; i++ {
+		b := l.blocks[i]
+		copy(b, zero64)
+		b[0] = 0x80
+		b[62] = 0x04
+	}
+	blockLanes(l.stats[:nl], l.blocks[:nl])
+	for i := 0; i < nl; i++ {
+		sha256.Int2Bytes(l.stats[i], outs[i])
+	}
+}
+
+//chains advances chains in[i] from starts[i] by steps[i] into out[i]
+//for i in [from, to), up to lanes chains in lockstep.
+func (l *hashLanes) chains(in [][]byte, starts, steps []byte, p *prf, addrs addr, out [][]byte, from, to int) {
+	for g := from; g < to; g += lanes {
+		end := g + lanes
+		if end > to {
+			end = to
+		}
+		var max byte
+		for j := g; j < end; j++ {
+			copy(out[j], in[j])
+			if steps[j] > max {
+				max = steps[j]
+			}
+		}
+		for i := byte(0); i < max; i++ {
+			nl := 0
+			for j := g; j < end; j++ {
+				if i >= steps[j] {
+					continue
+				}
+				a := addr(l.as[nl])
+				copy(a, addrs)
+				a.set(adrChain, uint32(j))
+				a.set(adrHash, uint32(starts[j]+i))
+				a.set(adrKM, 0)
+				l.outs[nl] = out[j]
+				nl++
+			}
+			l.prf(p, l.as[:nl], l.keys[:nl])
+			for k := 0; k < nl; k++ {
+				addr(l.as[k]).set(adrKM, 1)
+			}
+			l.prf(p, l.as[:nl], l.bm0s[:nl])
+			for k := 0; k < nl; k++ {
+				xorWords(l.bm0s[k], l.outs[k], l.bm0s[k])
+			}
+			l.hashF(l.keys[:nl], l.bm0s[:nl], l.outs[:nl])
+		}
+	}
+}
+
+//randHash computes randHash(l.lefts[i], l.rights[i], p, l.as[i], l.outs[i]) for i < nl.
+//Arguments must be set in l. outs may overlap with lefts and rights.
+func (l *hashLanes) randHash(p *prf, nl int) {
+	lefts, rights, outs := l.lefts, l.rights, l.outs
+	as := l.as[:nl]
+	for i := 0; i < nl; i++ {
+		addr(as[i]).set(adrKM, 0)
+	}
+	l.prf(p, as, l.keys[:nl])
+	for i := 0; i < nl; i++ {
+		addr(as[i]).set(adrKM, 1)
+	}
+	l.prf(p, as, l.bm0s[:nl])
+	for i := 0; i < nl; i++ {
+		addr(as[i]).set(adrKM, 2)
+	}
+	l.prf(p, as, l.bm1s[:nl])
+	for i := 0; i < nl; i++ {
+		xorWords(l.bm0s[i], lefts[i], l.bm0s[i])
+		xorWords(l.bm1s[i], rights[i], l.bm1s[i])
+	}
+	l.hashH(l.keys[:nl], l.bm0s[:nl], l.bm1s[:nl], outs[:nl])
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build amd64 && !noasm
+// +build amd64,!noasm
+
+package xmss
+
+import "encoding/binary"
+
+//useAVX2 is true if blockLanes hashes blocks in lanes of AVX2 registers.
+//CPUs with SHA extensions hash blocks one by one by sha256.Block faster than 8 lanes by AVX2.
+var useAVX2 = hasAVX2() && !hasSHA()
+
+//minLanesAVX2 is the min number of lanes which are hashed by AVX2.
+//Fewer blocks are hashed one by one, because AVX2 always computes 8 lanes.
+const minLanesAVX2 = 2
+
+//go:noescape
+func blockAVX2x8(st *[8][8]uint32, w *[64][8]uint32)
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
+
+//hasAVX2 returns true if the CPU and the OS support AVX2.
+func hasAVX2() bool {
+	max, _, _, _ := cpuid(0, 0)
+	if max < 7 {
+		return false
+	}
+	//OSXSAVE and AVX
+	if _, _, c, _ := cpuid(1, 0); c&(1<<27) == 0 || c&(1<<28) == 0 {
+		return false
+	}
+	//XMM and YMM states are saved by the OS.
+	if a, _ := xgetbv(); a&6 != 6 {
+		return false
+	}
+	_, b, _, _ := cpuid(7, 0)
+	return b&(1<<5) != 0
+}
+
+//hasSHA returns true if the CPU supports SHA extensions.
+func hasSHA() bool {
+	max, _, _, _ := cpuid(0, 0)
+	if max < 7 {
+		return false
+	}
+	_, b, _, _ := cpuid(7, 0)
+	return b&(1<<29) != 0
+}
+
+//blockLanesArch compresses blocks[i] into stats[i] for all lanes by AVX2 if useAVX2 is true.
+func blockLanesArch(stats [][]uint32, blocks [][]byte) {
+	if !useAVX2 || len(stats) < minLanesAVX2 {
+		blockLanesGeneric(stats, blocks)
+		return
+	}
+	blockLanesAVX2(stats, blocks)
+}
+
+//blockLanesAVX2 compresses blocks[i] into stats[i] for up to 8 lanes at once by AVX2.
+func blockLanesAVX2(stats [][]uint32, blocks [][]byte) {
+	var st [8][8]uint32
+	var w [64][8]uint32
+	for i := range stats {
+		for j := range st {
+			st[j][i] = stats[i][j]
+		}
+		b := blocks[i]
+		for t := 0; t < 16; t++ {
+			w[t][i] = binary.BigEndian.Uint32(b[4*t:])
+		}
+	}
+	blockAVX2x8(&st, &w)
+	for i := range stats {
+		for j := range st {
+			stats[i][j] = st[j][i]
+		}
+	}
+	//blocks may be made from secrets.
+	st = [8][8]uint32{}
+	w = [64][8]uint32{}
+}
@@ -0,0 +1,503 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build amd64 && !noasm
+// +build amd64,!noasm
+
+#include "textflag.h"
+
+//SHA-256 of 8 independent blocks in the lanes of AVX2 registers.
+//Word j of the state of lane i is at st+32*j+4*i,
+//and word t of the message schedule of lane i is at w+32*t+4*i.
+
+//ROTR xors x rotated right by r into acc, using tmp.
+#define ROTR(x, r, l, acc, tmp) \
+	VPSRLD $r, x, tmp; \
+	VPXOR  tmp, acc, acc; \
+	VPSLLD $l, x, tmp; \
+	VPXOR  tmp, acc, acc
+
+//SCHED computes W[t] from W[t-16], W[t-15], W[t-7] and W[t-2] at DI+off.
+#define SCHED(off) \
+	VMOVDQU (off-480)(DI), Y0; \
+	VPSRLD  $3, Y0, Y1; \
+	ROTR(Y0, 7, 25, Y1, Y2); \
+	ROTR(Y0, 18, 14, Y1, Y2); \
+	VMOVDQU (off-64)(DI), Y0; \
+	VPSRLD  $10, Y0, Y3; \
+	ROTR(Y0, 17, 15, Y3, Y2); \
+	ROTR(Y0, 19, 13, Y3, Y2); \
+	VPADDD  Y3, Y1, Y1; \
+	VPADDD  (off-512)(DI), Y1, Y1; \
+	VPADDD  (off-224)(DI), Y1, Y1; \
+	VMOVDQU Y1, off(DI)
+
+//ROUND computes a round with W[t]+K[t] at DI+off and R8+off, leaving the new a in h.
+#define ROUND(a, b, c, d, e, f, g, h, off) \
+	VPADDD off(DI), h, h; \
+	VPADDD off(R8), h, h; \
+	VPSRLD $6, e, Y8; \
+	VPSLLD $26, e, Y9; \
+	VPXOR  Y9, Y8, Y8; \
+	ROTR(e, 11, 21, Y8, Y9); \
+	ROTR(e, 25, 7, Y8, Y9); \
+	VPADDD Y8, h, h; \
+	VPAND  f, e, Y8; \
+	VPANDN g, e, Y9; \
+	VPXOR  Y9, Y8, Y8; \
+	VPADDD Y8, h, h; \
+	VPADDD h, d, d; \
+	VPSRLD $2, a, Y8; \
+	VPSLLD $30, a, Y9; \
+	VPXOR  Y9, Y8, Y8; \
+	ROTR(a, 13, 19, Y8, Y9); \
+	ROTR(a, 22, 10, Y8, Y9); \
+	VPADDD Y8, h, h; \
+	VPOR   b, a, Y8; \
+	VPAND  c, Y8, Y8; \
+	VPAND  b, a, Y9; \
+	VPOR   Y9, Y8, Y8; \
+	VPADDD Y8, h, h
+
+//func blockAVX2x8(st *[8][8]uint32, w *[64][8]uint32)
+TEXT ·blockAVX2x8(SB), NOSPLIT, $0-16
+	MOVQ st+0(FP), SI
+	MOVQ w+8(FP), DI
+	LEAQ sha256K8<>(SB), R8
+
+	SCHED(512)
+	SCHED(544)
+	SCHED(576)
+	SCHED(608)
+	SCHED(640)
+	SCHED(672)
+	SCHED(704)
+	SCHED(736)
+	SCHED(768)
+	SCHED(800)
+	SCHED(832)
+	SCHED(864)
+	SCHED(896)
+	SCHED(928)
+	SCHED(960)
+	SCHED(992)
+	SCHED(1024)
+	SCHED(1056)
+	SCHED(1088)
+	SCHED(1120)
+	SCHED(1152)
+	SCHED(1184)
+	SCHED(1216)
+	SCHED(1248)
+	SCHED(1280)
+	SCHED(1312)
+	SCHED(1344)
+	SCHED(1376)
+	SCHED(1408)
+	SCHED(1440)
+	SCHED(1472)
+	SCHED(1504)
+	SCHED(1536)
+	SCHED(1568)
+	SCHED(1600)
+	SCHED(1632)
+	SCHED(1664)
+	SCHED(1696)
+	SCHED(1728)
+	SCHED(1760)
+	SCHED(1792)
+	SCHED(1824)
+	SCHED(1856)
+	SCHED(1888)
+	SCHED(1920)
+	SCHED(1952)
+	SCHED(1984)
+	SCHED(2016)
+
+	VMOVDQU 0(SI), Y0
+	VMOVDQU 32(SI), Y1
+	VMOVDQU 64(SI), Y2
+	VMOVDQU 96(SI), Y3
+	VMOVDQU 128(SI), Y4
+	VMOVDQU 160(SI), Y5
+	VMOVDQU 192(SI), Y6
+	VMOVDQU 224(SI), Y7
+
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 0)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 32)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 64)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 96)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 128)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 160)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 192)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 224)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 256)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 288)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 320)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 352)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 384)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 416)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 448)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 480)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 512)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 544)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 576)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 608)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 640)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 672)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 704)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 736)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 768)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 800)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 832)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 864)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 896)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 928)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 960)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 992)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 1024)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 1056)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 1088)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 1120)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 1152)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 1184)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 1216)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 1248)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 1280)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 1312)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 1344)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 1376)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 1408)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 1440)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 1472)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 1504)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 1536)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 1568)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 1600)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 1632)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 1664)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 1696)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 1728)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 1760)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 1792)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 1824)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 1856)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 1888)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 1920)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 1952)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 1984)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 2016)
+
+	VPADDD  0(SI), Y0, Y0
+	VMOVDQU Y0, 0(SI)
+	VPADDD  32(SI), Y1, Y1
+	VMOVDQU Y1, 32(SI)
+	VPADDD  64(SI), Y2, Y2
+	VMOVDQU Y2, 64(SI)
+	VPADDD  96(SI), Y3, Y3
+	VMOVDQU Y3, 96(SI)
+	VPADDD  128(SI), Y4, Y4
+	VMOVDQU Y4, 128(SI)
+	VPADDD  160(SI), Y5, Y5
+	VMOVDQU Y5, 160(SI)
+	VPADDD  192(SI), Y6, Y6
+	VMOVDQU Y6, 192(SI)
+	VPADDD  224(SI), Y7, Y7
+	VMOVDQU Y7, 224(SI)
+	VZEROUPPER
+	RET
+
+//func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+//func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	BYTE $0x0f; BYTE $0x01; BYTE $0xd0 //XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+//K of SHA-256, each repeated for 8 lanes.
+DATA sha256K8<>+0x000(SB)/8, $0x428a2f98428a2f98
+DATA sha256K8<>+0x008(SB)/8, $0x428a2f98428a2f98
+DATA sha256K8<>+0x010(SB)/8, $0x428a2f98428a2f98
+DATA sha256K8<>+0x018(SB)/8, $0x428a2f98428a2f98
+DATA sha256K8<>+0x020(SB)/8, $0x7137449171374491
+DATA sha256K8<>+0x028(SB)/8, $0x7137449171374491
+DATA sha256K8<>+0x030(SB)/8, $0x7137449171374491
+DATA sha256K8<>+0x038(SB)/8, $0x7137449171374491
+DATA sha256K8<>+0x040(SB)/8, $0xb5c0fbcfb5c0fbcf
+DATA sha256K8<>+0x048(SB)/8, $0xb5c0fbcfb5c0fbcf
+DATA sha256K8<>+0x050(SB)/8, $0xb5c0fbcfb5c0fbcf
+DATA sha256K8<>+0x058(SB)/8, $0xb5c0fbcfb5c0fbcf
+DATA sha256K8<>+0x060(SB)/8, $0xe9b5dba5e9b5dba5
+DATA sha256K8<>+0x068(SB)/8, $0xe9b5dba5e9b5dba5
+DATA sha256K8<>+0x070(SB)/8, $0xe9b5dba5e9b5dba5
+DATA sha256K8<>+0x078(SB)/8, $0xe9b5dba5e9b5dba5
+DATA sha256K8<>+0x080(SB)/8, $0x3956c25b3956c25b
+DATA sha256K8<>+0x088(SB)/8, $0x3956c25b3956c25b
+DATA sha256K8<>+0x090(SB)/8, $0x3956c25b3956c25b
+DATA sha256K8<>+0x098(SB)/8, $0x3956c25b3956c25b
+DATA sha256K8<>+0x0a0(SB)/8, $0x59f111f159f111f1
+DATA sha256K8<>+0x0a8(SB)/8, $0x59f111f159f111f1
+DATA sha256K8<>+0x0b0(SB)/8, $0x59f111f159f111f1
+DATA sha256K8<>+0x0b8(SB)/8, $0x59f111f159f111f1
+DATA sha256K8<>+0x0c0(SB)/8, $0x923f82a4923f82a4
+DATA sha256K8<>+0x0c8(SB)/8, $0x923f82a4923f82a4
+DATA sha256K8<>+0x0d0(SB)/8, $0x923f82a4923f82a4
+DATA sha256K8<>+0x0d8(SB)/8, $0x923f82a4923f82a4
+DATA sha256K8<>+0x0e0(SB)/8, $0xab1c5ed5ab1c5ed5
+DATA sha256K8<>+0x0e8(SB)/8, $0xab1c5ed5ab1c5ed5
+DATA sha256K8<>+0x0f0(SB)/8, $0xab1c5ed5ab1c5ed5
+DATA sha256K8<>+0x0f8(SB)/8, $0xab1c5ed5ab1c5ed5
+DATA sha256K8<>+0x100(SB)/8, $0xd807aa98d807aa98
+DATA sha256K8<>+0x108(SB)/8, $0xd807aa98d807aa98
+DATA sha256K8<>+0x110(SB)/8, $0xd807aa98d807aa98
+DATA sha256K8<>+0x118(SB)/8, $0xd807aa98d807aa98
+DATA sha256K8<>+0x120(SB)/8, $0x12835b0112835b01
+DATA sha256K8<>+0x128(SB)/8, $0x12835b0112835b01
+DATA sha256K8<>+0x130(SB)/8, $0x12835b0112835b01
+DATA sha256K8<>+0x138(SB)/8, $0x12835b0112835b01
+DATA sha256K8<>+0x140(SB)/8, $0x243185be243185be
+DATA sha256K8<>+0x148(SB)/8, $0x243185be243185be
+DATA sha256K8<>+0x150(SB)/8, $0x243185be243185be
+DATA sha256K8<>+0x158(SB)/8, $0x243185be243185be
+DATA sha256K8<>+0x160(SB)/8, $0x550c7dc3550c7dc3
+DATA sha256K8<>+0x168(SB)/8, $0x550c7dc3550c7dc3
+DATA sha256K8<>+0x170(SB)/8, $0x550c7dc3550c7dc3
+DATA sha256K8<>+0x178(SB)/8, $0x550c7dc3550c7dc3
+DATA sha256K8<>+0x180(SB)/8, $0x72be5d7472be5d74
+DATA sha256K8<>+0x188(SB)/8, $0x72be5d7472be5d74
+DATA sha256K8<>+0x190(SB)/8, $0x72be5d7472be5d74
+DATA sha256K8<>+0x198(SB)/8, $0x72be5d7472be5d74
+DATA sha256K8<>+0x1a0(SB)/8, $0x80deb1fe80deb1fe
+DATA sha256K8<>+0x1a8(SB)/8, $0x80deb1fe80deb1fe
+DATA sha256K8<>+0x1b0(SB)/8, $0x80deb1fe80deb1fe
+DATA sha256K8<>+0x1b8(SB)/8, $0x80deb1fe80deb1fe
+DATA sha256K8<>+0x1c0(SB)/8, $0x9bdc06a79bdc06a7
+DATA sha256K8<>+0x1c8(SB)/8, $0x9bdc06a79bdc06a7
+DATA sha256K8<>+0x1d0(SB)/8, $0x9bdc06a79bdc06a7
+DATA sha256K8<>+0x1d8(SB)/8, $0x9bdc06a79bdc06a7
+DATA sha256K8<>+0x1e0(SB)/8, $0xc19bf174c19bf174
+DATA sha256K8<>+0x1e8(SB)/8, $0xc19bf174c19bf174
+DATA sha256K8<>+0x1f0(SB)/8, $0xc19bf174c19bf174
+DATA sha256K8<>+0x1f8(SB)/8, $0xc19bf174c19bf174
+DATA sha256K8<>+0x200(SB)/8, $0xe49b69c1e49b69c1
+DATA sha256K8<>+0x208(SB)/8, $0xe49b69c1e49b69c1
+DATA sha256K8<>+0x210(SB)/8, $0xe49b69c1e49b69c1
+DATA sha256K8<>+0x218(SB)/8, $0xe49b69c1e49b69c1
+DATA sha256K8<>+0x220(SB)/8, $0xefbe4786efbe4786
+DATA sha256K8<>+0x228(SB)/8, $0xefbe4786efbe4786
+DATA sha256K8<>+0x230(SB)/8, $0xefbe4786efbe4786
+DATA sha256K8<>+0x238(SB)/8, $0xefbe4786efbe4786
+DATA sha256K8<>+0x240(SB)/8, $0x0fc19dc60fc19dc6
+DATA sha256K8<>+0x248(SB)/8, $0x0fc19dc60fc19dc6
+DATA sha256K8<>+0x250(SB)/8, $0x0fc19dc60fc19dc6
+DATA sha256K8<>+0x258(SB)/8, $0x0fc19dc60fc19dc6
+DATA sha256K8<>+0x260(SB)/8, $0x240ca1cc240ca1cc
+DATA sha256K8<>+0x268(SB)/8, $0x240ca1cc240ca1cc
+DATA sha256K8<>+0x270(SB)/8, $0x240ca1cc240ca1cc
+DATA sha256K8<>+0x278(SB)/8, $0x240ca1cc240ca1cc
+DATA sha256K8<>+0x280(SB)/8, $0x2de92c6f2de92c6f
+DATA sha256K8<>+0x288(SB)/8, $0x2de92c6f2de92c6f
+DATA sha256K8<>+0x290(SB)/8, $0x2de92c6f2de92c6f
+DATA sha256K8<>+0x298(SB)/8, $0x2de92c6f2de92c6f
+DATA sha256K8<>+0x2a0(SB)/8, $0x4a7484aa4a7484aa
+DATA sha256K8<>+0x2a8(SB)/8, $0x4a7484aa4a7484aa
+DATA sha256K8<>+0x2b0(SB)/8, $0x4a7484aa4a7484aa
+DATA sha256K8<>+0x2b8(SB)/8, $0x4a7484aa4a7484aa
+DATA sha256K8<>+0x2c0(SB)/8, $0x5cb0a9dc5cb0a9dc
+DATA sha256K8<>+0x2c8(SB)/8, $0x5cb0a9dc5cb0a9dc
+DATA sha256K8<>+0x2d0(SB)/8, $0x5cb0a9dc5cb0a9dc
+DATA sha256K8<>+0x2d8(SB)/8, $0x5cb0a9dc5cb0a9dc
+DATA sha256K8<>+0x2e0(SB)/8, $0x76f988da76f988da
+DATA sha256K8<>+0x2e8(SB)/8, $0x76f988da76f988da
+DATA sha256K8<>+0x2f0(SB)/8, $0x76f988da76f988da
+DATA sha256K8<>+0x2f8(SB)/8, $0x76f988da76f988da
+DATA sha256K8<>+0x300(SB)/8, $0x983e5152983e5152
+DATA sha256K8<>+0x308(SB)/8, $0x983e5152983e5152
+DATA sha256K8<>+0x310(SB)/8, $0x983e5152983e5152
+DATA sha256K8<>+0x318(SB)/8, $0x983e5152983e5152
+DATA sha256K8<>+0x320(SB)/8, $0xa831c66da831c66d
+DATA sha256K8<>+0x328(SB)/8, $0xa831c66da831c66d
+DATA sha256K8<>+0x330(SB)/8, $0xa831c66da831c66d
+DATA sha256K8<>+0x338(SB)/8, $0xa831c66da831c66d
+DATA sha256K8<>+0x340(SB)/8, $0xb00327c8b00327c8
+DATA sha256K8<>+0x348(SB)/8, $0xb00327c8b00327c8
+DATA sha256K8<>+0x350(SB)/8, $0xb00327c8b00327c8
+DATA sha256K8<>+0x358(SB)/8, $0xb00327c8b00327c8
+DATA sha256K8<>+0x360(SB)/8, $0xbf597fc7bf597fc7
+DATA sha256K8<>+0x368(SB)/8, $0xbf597fc7bf597fc7
+DATA sha256K8<>+0x370(SB)/8, $0xbf597fc7bf597fc7
+DATA sha256K8<>+0x378(SB)/8, $0xbf597fc7bf597fc7
+DATA sha256K8<>+0x380(SB)/8, $0xc6e00bf3c6e00bf3
+DATA sha256K8<>+0x388(SB)/8, $0xc6e00bf3c6e00bf3
+DATA sha256K8<>+0x390(SB)/8, $0xc6e00bf3c6e00bf3
+DATA sha256K8<>+0x398(SB)/8, $0xc6e00bf3c6e00bf3
+DATA sha256K8<>+0x3a0(SB)/8, $0xd5a79147d5a79147
+DATA sha256K8<>+0x3a8(SB)/8, $0xd5a79147d5a79147
+DATA sha256K8<>+0x3b0(SB)/8, $0xd5a79147d5a79147
+DATA sha256K8<>+0x3b8(SB)/8, $0xd5a79147d5a79147
+DATA sha256K8<>+0x3c0(SB)/8, $0x06ca635106ca6351
+DATA sha256K8<>+0x3c8(SB)/8, $0x06ca635106ca6351
+DATA sha256K8<>+0x3d0(SB)/8, $0x06ca635106ca6351
+DATA sha256K8<>+0x3d8(SB)/8, $0x06ca635106ca6351
+DATA sha256K8<>+0x3e0(SB)/8, $0x1429296714292967
+DATA sha256K8<>+0x3e8(SB)/8, $0x1429296714292967
+DATA sha256K8<>+0x3f0(SB)/8, $0x1429296714292967
+DATA sha256K8<>+0x3f8(SB)/8, $0x1429296714292967
+DATA sha256K8<>+0x400(SB)/8, $0x27b70a8527b70a85
+DATA sha256K8<>+0x408(SB)/8, $0x27b70a8527b70a85
+DATA sha256K8<>+0x410(SB)/8, $0x27b70a8527b70a85
+DATA sha256K8<>+0x418(SB)/8, $0x27b70a8527b70a85
+DATA sha256K8<>+0x420(SB)/8, $0x2e1b21382e1b2138
+DATA sha256K8<>+0x428(SB)/8, $0x2e1b21382e1b2138
+DATA sha256K8<>+0x430(SB)/8, $0x2e1b21382e1b2138
+DATA sha256K8<>+0x438(SB)/8, $0x2e1b21382e1b2138
+DATA sha256K8<>+0x440(SB)/8, $0x4d2c6dfc4d2c6dfc
+DATA sha256K8<>+0x448(SB)/8, $0x4d2c6dfc4d2c6dfc
+DATA sha256K8<>+0x450(SB)/8, $0x4d2c6dfc4d2c6dfc
+DATA sha256K8<>+0x458(SB)/8, $0x4d2c6dfc4d2c6dfc
+DATA sha256K8<>+0x460(SB)/8, $0x53380d1353380d13
+DATA sha256K8<>+0x468(SB)/8, $0x53380d1353380d13
+DATA sha256K8<>+0x470(SB)/8, $0x53380d1353380d13
+DATA sha256K8<>+0x478(SB)/8, $0x53380d1353380d13
+DATA sha256K8<>+0x480(SB)/8, $0x650a7354650a7354
+DATA sha256K8<>+0x488(SB)/8, $0x650a7354650a7354
+DATA sha256K8<>+0x490(SB)/8, $0x650a7354650a7354
+DATA sha256K8<>+0x498(SB)/8, $0x650a7354650a7354
+DATA sha256K8<>+0x4a0(SB)/8, $0x766a0abb766a0abb
+DATA sha256K8<>+0x4a8(SB)/8, $0x766a0abb766a0abb
+DATA sha256K8<>+0x4b0(SB)/8, $0x766a0abb766a0abb
+DATA sha256K8<>+0x4b8(SB)/8, $0x766a0abb766a0abb
+DATA sha256K8<>+0x4c0(SB)/8, $0x81c2c92e81c2c92e
+DATA sha256K8<>+0x4c8(SB)/8, $0x81c2c92e81c2c92e
+DATA sha256K8<>+0x4d0(SB)/8, $0x81c2c92e81c2c92e
+DATA sha256K8<>+0x4d8(SB)/8, $0x81c2c92e81c2c92e
+DATA sha256K8<>+0x4e0(SB)/8, $0x92722c8592722c85
+DATA sha256K8<>+0x4e8(SB)/8, $0x92722c8592722c85
+DATA sha256K8<>+0x4f0(SB)/8, $0x92722c8592722c85
+DATA sha256K8<>+0x4f8(SB)/8, $0x92722c8592722c85
+DATA sha256K8<>+0x500(SB)/8, $0xa2bfe8a1a2bfe8a1
+DATA sha256K8<>+0x508(SB)/8, $0xa2bfe8a1a2bfe8a1
+DATA sha256K8<>+0x510(SB)/8, $0xa2bfe8a1a2bfe8a1
+DATA sha256K8<>+0x518(SB)/8, $0xa2bfe8a1a2bfe8a1
+DATA sha256K8<>+0x520(SB)/8, $0xa81a664ba81a664b
+DATA sha256K8<>+0x528(SB)/8, $0xa81a664ba81a664b
+DATA sha256K8<>+0x530(SB)/8, $0xa81a664ba81a664b
+DATA sha256K8<>+0x538(SB)/8, $0xa81a664ba81a664b
+DATA sha256K8<>+0x540(SB)/8, $0xc24b8b70c24b8b70
+DATA sha256K8<>+0x548(SB)/8, $0xc24b8b70c24b8b70
+DATA sha256K8<>+0x550(SB)/8, $0xc24b8b70c24b8b70
+DATA sha256K8<>+0x558(SB)/8, $0xc24b8b70c24b8b70
+DATA sha256K8<>+0x560(SB)/8, $0xc76c51a3c76c51a3
+DATA sha256K8<>+0x568(SB)/8, $0xc76c51a3c76c51a3
+DATA sha256K8<>+0x570(SB)/8, $0xc76c51a3c76c51a3
+DATA sha256K8<>+0x578(SB)/8, $0xc76c51a3c76c51a3
+DATA sha256K8<>+0x580(SB)/8, $0xd192e819d192e819
+DATA sha256K8<>+0x588(SB)/8, $0xd192e819d192e819
+DATA sha256K8<>+0x590(SB)/8, $0xd192e819d192e819
+DATA sha256K8<>+0x598(SB)/8, $0xd192e819d192e819
+DATA sha256K8<>+0x5a0(SB)/8, $0xd6990624d6990624
+DATA sha256K8<>+0x5a8(SB)/8, $0xd6990624d6990624
+DATA sha256K8<>+0x5b0(SB)/8, $0xd6990624d6990624
+DATA sha256K8<>+0x5b8(SB)/8, $0xd6990624d6990624
+DATA sha256K8<>+0x5c0(SB)/8, $0xf40e3585f40e3585
+DATA sha256K8<>+0x5c8(SB)/8, $0xf40e3585f40e3585
+DATA sha256K8<>+0x5d0(SB)/8, $0xf40e3585f40e3585
+DATA sha256K8<>+0x5d8(SB)/8, $0xf40e3585f40e3585
+DATA sha256K8<>+0x5e0(SB)/8, $0x106aa070106aa070
+DATA sha256K8<>+0x5e8(SB)/8, $0x106aa070106aa070
+DATA sha256K8<>+0x5f0(SB)/8, $0x106aa070106aa070
+DATA sha256K8<>+0x5f8(SB)/8, $0x106aa070106aa070
+DATA sha256K8<>+0x600(SB)/8, $0x19a4c11619a4c116
+DATA sha256K8<>+0x608(SB)/8, $0x19a4c11619a4c116
+DATA sha256K8<>+0x610(SB)/8, $0x19a4c11619a4c116
+DATA sha256K8<>+0x618(SB)/8, $0x19a4c11619a4c116
+DATA sha256K8<>+0x620(SB)/8, $0x1e376c081e376c08
+DATA sha256K8<>+0x628(SB)/8, $0x1e376c081e376c08
+DATA sha256K8<>+0x630(SB)/8, $0x1e376c081e376c08
+DATA sha256K8<>+0x638(SB)/8, $0x1e376c081e376c08
+DATA sha256K8<>+0x640(SB)/8, $0x2748774c2748774c
+DATA sha256K8<>+0x648(SB)/8, $0x2748774c2748774c
+DATA sha256K8<>+0x650(SB)/8, $0x2748774c2748774c
+DATA sha256K8<>+0x658(SB)/8, $0x2748774c2748774c
+DATA sha256K8<>+0x660(SB)/8, $0x34b0bcb534b0bcb5
+DATA sha256K8<>+0x668(SB)/8, $0x34b0bcb534b0bcb5
+DATA sha256K8<>+0x670(SB)/8, $0x34b0bcb534b0bcb5
+DATA sha256K8<>+0x678(SB)/8, $0x34b0bcb534b0bcb5
+DATA sha256K8<>+0x680(SB)/8, $0x391c0cb3391c0cb3
+DATA sha256K8<>+0x688(SB)/8, $0x391c0cb3391c0cb3
+DATA sha256K8<>+0x690(SB)/8, $0x391c0cb3391c0cb3
+DATA sha256K8<>+0x698(SB)/8, $0x391c0cb3391c0cb3
+DATA sha256K8<>+0x6a0(SB)/8, $0x4ed8aa4a4ed8aa4a
+DATA sha256K8<>+0x6a8(SB)/8, $0x4ed8aa4a4ed8aa4a
+DATA sha256K8<>+0x6b0(SB)/8, $0x4ed8aa4a4ed8aa4a
+DATA sha256K8<>+0x6b8(SB)/8, $0x4ed8aa4a4ed8aa4a
+DATA sha256K8<>+0x6c0(SB)/8, $0x5b9cca4f5b9cca4f
+DATA sha256K8<>+0x6c8(SB)/8, $0x5b9cca4f5b9cca4f
+DATA sha256K8<>+0x6d0(SB)/8, $0x5b9cca4f5b9cca4f
+DATA sha256K8<>+0x6d8(SB)/8, $0x5b9cca4f5b9cca4f
+DATA sha256K8<>+0x6e0(SB)/8, $0x682e6ff3682e6ff3
+DATA sha256K8<>+0x6e8(SB)/8, $0x682e6ff3682e6ff3
+DATA sha256K8<>+0x6f0(SB)/8, $0x682e6ff3682e6ff3
+DATA sha256K8<>+0x6f8(SB)/8, $0x682e6ff3682e6ff3
+DATA sha256K8<>+0x700(SB)/8, $0x748f82ee748f82ee
+DATA sha256K8<>+0x708(SB)/8, $0x748f82ee748f82ee
+DATA sha256K8<>+0x710(SB)/8, $0x748f82ee748f82ee
+DATA sha256K8<>+0x718(SB)/8, $0x748f82ee748f82ee
+DATA sha256K8<>+0x720(SB)/8, $0x78a5636f78a5636f
+DATA sha256K8<>+0x728(SB)/8, $0x78a5636f78a5636f
+DATA sha256K8<>+0x730(SB)/8, $0x78a5636f78a5636f
+DATA sha256K8<>+0x738(SB)/8, $0x78a5636f78a5636f
+DATA sha256K8<>+0x740(SB)/8, $0x84c8781484c87814
+DATA sha256K8<>+0x748(SB)/8, $0x84c8781484c87814
+DATA sha256K8<>+0x750(SB)/8, $0x84c8781484c87814
+DATA sha256K8<>+0x758(SB)/8, $0x84c8781484c87814
+DATA sha256K8<>+0x760(SB)/8, $0x8cc702088cc70208
+DATA sha256K8<>+0x768(SB)/8, $0x8cc702088cc70208
+DATA sha256K8<>+0x770(SB)/8, $0x8cc702088cc70208
+DATA sha256K8<>+0x778(SB)/8, $0x8cc702088cc70208
+DATA sha256K8<>+0x780(SB)/8, $0x90befffa90befffa
+DATA sha256K8<>+0x788(SB)/8, $0x90befffa90befffa
+DATA sha256K8<>+0x790(SB)/8, $0x90befffa90befffa
+DATA sha256K8<>+0x798(SB)/8, $0x90befffa90befffa
+DATA sha256K8<>+0x7a0(SB)/8, $0xa4506ceba4506ceb
+DATA sha256K8<>+0x7a8(SB)/8, $0xa4506ceba4506ceb
+DATA sha256K8<>+0x7b0(SB)/8, $0xa4506ceba4506ceb
+DATA sha256K8<>+0x7b8(SB)/8, $0xa4506ceba4506ceb
+DATA sha256K8<>+0x7c0(SB)/8, $0xbef9a3f7bef9a3f7
+DATA sha256K8<>+0x7c8(SB)/8, $0xbef9a3f7bef9a3f7
+DATA sha256K8<>+0x7d0(SB)/8, $0xbef9a3f7bef9a3f7
+DATA sha256K8<>+0x7d8(SB)/8, $0xbef9a3f7bef9a3f7
+DATA sha256K8<>+0x7e0(SB)/8, $0xc67178f2c67178f2
+DATA sha256K8<>+0x7e8(SB)/8, $0xc67178f2c67178f2
+DATA sha256K8<>+0x7f0(SB)/8, $0xc67178f2c67178f2
+DATA sha256K8<>+0x7f8(SB)/8, $0xc67178f2c67178f2
+GLOBL sha256K8<>(SB), RODATA, $2048
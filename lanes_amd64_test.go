@@ -0,0 +1,84 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build amd64 && !noasm
+// +build amd64,!noasm
+
+package xmss
+
+import (
+	"bytes"
+	"testing"
+)
+
+//forceAVX2 makes blockLanes hash lanes by AVX2 if on and the CPU supports AVX2,
+//even if the CPU has SHA extensions, until the returned function is called.
+func forceAVX2(on bool) func() {
+	old := useAVX2
+	useAVX2 = on && hasAVX2()
+	return func() {
+		useAVX2 = old
+	}
+}
+
+func TestBlockLanesAVX2(t *testing.T) {
+	if !hasAVX2() {
+		t.Skip("AVX2 is not supported")
+	}
+	testBlockLanes(t, blockLanesAVX2)
+}
+
+//TestForceAVX2 runs the AVX2 kernel through blockLanes, which is not used on CPUs with SHA extensions
+//by default, and compares keys and signatures with the ones by blockLanesGeneric.
+func TestForceAVX2(t *testing.T) {
+	if !hasAVX2() {
+		t.Skip("AVX2 is not supported")
+	}
+	defer forceAVX2(true)()
+	testBlockLanes(t, blockLanes)
+	TestHashLanes(t)
+
+	seed := generateSeed()
+	msg := []byte("This is a test for the AVX2 kernel.")
+	var pks, sigs [2][]byte
+	for i, on := range []bool{false, true} {
+		restore := forceAVX2(on)
+		mer := NewMerkle(4, seed)
+		sig, err := mer.Sign(msg)
+		restore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pks[i], sigs[i] = mer.PublicKey(), sig
+	}
+	if !bytes.Equal(pks[0], pks[1]) || !bytes.Equal(sigs[0], sigs[1]) {
+		t.Error("AVX2 kernel makes different keys or signatures")
+	}
+	if !Verify(sigs[1], msg, pks[1]) {
+		t.Error("XMSS sig is incorrect")
+	}
+}
+
+func BenchmarkBlockLanesAVX2(b *testing.B) {
+	if !hasAVX2() {
+		b.Skip("AVX2 is not supported")
+	}
+	benchmarkBlockLanes(b, blockLanesAVX2)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestHashLanes(t *testing.T) {
+	p := newPRF(nil)
+	addrs := make(addr, 32)
+	addrs.set(adrOTS, 5)
+	in := make([][]byte, wlen)
+	out := make([][]byte, wlen)
+	starts := make([]byte, wlen)
+	steps := make([]byte, wlen)
+	rnd := make([]byte, wlen)
+	if _, err := rand.Read(rnd); err != nil {
+		t.Fatal(err)
+	}
+	for i := range in {
+		in[i] = make([]byte, n)
+		out[i] = make([]byte, n)
+		if _, err := rand.Read(in[i]); err != nil {
+			t.Fatal(err)
+		}
+		starts[i] = rnd[i] & 0xf
+		steps[i] = (rnd[i] >> 4) % (w - starts[i])
+	}
+	l := newHashLanes()
+	l.chains(in, starts, steps, p, addrs, out, 0, wlen)
+	for i := range in {
+		a := make(addr, 32)
+		copy(a, addrs)
+		a.set(adrChain, uint32(i))
+		o := make([]byte, n)
+		chain(in[i], starts[i], steps[i], p, a, o)
+		if !bytes.Equal(o, out[i]) {
+			t.Fatal("invalid chain", i)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		copy(l.as[i], make([]byte, 32))
+		addr(l.as[i]).set(adrIndex, uint32(i))
+		l.lefts[i], l.rights[i], l.outs[i] = in[i], in[3+i], out[i]
+	}
+	l.randHash(p, 3)
+	for i := 0; i < 3; i++ {
+		a := make(addr, 32)
+		a.set(adrIndex, uint32(i))
+		o := make([]byte, n)
+		randHash(in[i], in[3+i], p, a, o)
+		if !bytes.Equal(o, out[i]) {
+			t.Fatal("invalid randHash", i)
+		}
+	}
+}
+
+//testBlockLanes checks f against blockLanesGeneric for 1 to lanes blocks.
+func testBlockLanes(t *testing.T, f func(stats [][]uint32, blocks [][]byte)) {
+	for nl := 1; nl <= lanes; nl++ {
+		stats := make([][]uint32, nl)
+		expected := make([][]uint32, nl)
+		blocks := make([][]byte, nl)
+		for i := range blocks {
+			blocks[i] = make([]byte, 64)
+			if _, err := rand.Read(blocks[i]); err != nil {
+				t.Fatal(err)
+			}
+			stats[i] = make([]uint32, 8)
+			expected[i] = make([]uint32, 8)
+			for j := range stats[i] {
+				stats[i][j] = sha256Init[j] + uint32(i*j)
+				expected[i][j] = stats[i][j]
+			}
+		}
+		f(stats, blocks)
+		blockLanesGeneric(expected, blocks)
+		for i := range stats {
+			for j := range stats[i] {
+				if stats[i][j] != expected[i][j] {
+					t.Fatal("invalid hash", nl, i)
+				}
+			}
+		}
+	}
+}
+
+func TestBlockLanes(t *testing.T) {
+	testBlockLanes(t, blockLanes)
+}
+
+func benchmarkBlockLanes(b *testing.B, f func(stats [][]uint32, blocks [][]byte)) {
+	l := newHashLanes()
+	b.ReportAllocs()
+	b.SetBytes(lanes * 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f(l.stats, l.blocks)
+	}
+}
+
+func BenchmarkBlockLanes(b *testing.B) {
+	benchmarkBlockLanes(b, blockLanes)
+}
+
+func BenchmarkBlockLanesGeneric(b *testing.B) {
+	benchmarkBlockLanes(b, blockLanesGeneric)
+}
+
+func BenchmarkNewleaf(b *testing.B) {
+	m := NewMerkle(2, generateSeed())
+	s := &Stack{
+		stack:  make([]*NH, 0, b.N),
+		height: 20,
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.newleaf(m.priv, false)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+//States of Merkle and PrivKeyMT saved in JSON or msgpack before leaf ranges and MACs were
+//introduced have neither a range tag nor a MAC, so UnmarshalJSON and DecodeMsgpack reject them.
+//ImportLegacyMerkle and ImportLegacyPrivKeyMT load such states with the seed which the key was made
+//from, which authenticates the seeds in the state and makes a tag for the whole tree as the range.
+//The leaf number in a legacy state cannot be authenticated, so load only the latest state,
+//and save it again right after loading so that it has a MAC and epoch 0.
+
+//legacyPrivKeyMT is the state of PrivKeyMT saved before leaf ranges and MACs were introduced.
+type legacyPrivKeyMT struct {
+	Index  uint64
+	Merkle []*merkle
+	H      uint32
+	D      uint32
+}
+
+//decodeLegacy decodes dat in JSON or msgpack into v.
+func decodeLegacy(dat []byte, v interface{}) error {
+	if b := bytes.TrimSpace(dat); len(b) > 0 && b[0] == '{' {
+		return json.Unmarshal(b, v)
+	}
+	return msgpack.Unmarshal(dat, v)
+}
+
+//checkSeed returns an error if x was not made from seed.
+func (x *PrivKey) checkSeed(seed []byte) error {
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	defer wipe(wotsSeed)
+	defer wipe(msgSeed)
+	if !hmac.Equal(x.wotsPRF.seed, wotsSeed) || !hmac.Equal(x.msgPRF.seed, msgSeed) ||
+		!hmac.Equal(x.pubPRF.seed, pubSeed) {
+		return ValidationError("state was not made from the seed")
+	}
+	return nil
+}
+
+//legacyMerkle makes Merkle from s, a legacy state made from seed, whose range is the whole tree.
+func legacyMerkle(s *merkle, seed []byte) (*Merkle, error) {
+	if s.End != 0 || s.Tag != nil || s.MAC != nil {
+		return nil, ValidationError("not a legacy state")
+	}
+	m := &Merkle{}
+	if err := m.load(s); err != nil {
+		return nil, err
+	}
+	if err := m.priv.checkSeed(seed); err != nil {
+		return nil, err
+	}
+	m.end = 1 << m.Height
+	m.tag = m.rangeTag()
+	return m, nil
+}
+
+//ImportLegacyMerkle loads Merkle from dat, a state in JSON or msgpack saved before leaf ranges
+//and MACs were introduced, after checking that the key was made from seed by NewMerkle.
+func ImportLegacyMerkle(dat, seed []byte) (*Merkle, error) {
+	var s merkle
+	if err := decodeLegacy(dat, &s); err != nil {
+		return nil, err
+	}
+	m, err := legacyMerkle(&s, seed)
+	if err != nil {
+		if s.Priv != nil {
+			s.Priv.Destroy()
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+//privKeyMT makes PrivKeyMT from s, a legacy state made from seed, whose range is all indices.
+func (s *legacyPrivKeyMT) privKeyMT(seed []byte) (*PrivKeyMT, error) {
+	p := &PrivKeyMT{
+		index:  s.Index,
+		merkle: make([]*Merkle, len(s.Merkle)),
+		h:      s.H,
+		d:      s.D,
+		end:    numIndices(s.H),
+	}
+	for i, ms := range s.Merkle {
+		if ms == nil {
+			continue
+		}
+		m, err := legacyMerkle(ms, seed)
+		if err != nil {
+			return nil, err
+		}
+		p.merkle[i] = m
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	p.tag = p.rangeTag()
+	return p, nil
+}
+
+//ImportLegacyPrivKeyMT loads PrivKeyMT from dat, a state in JSON or msgpack saved before index ranges
+//and MACs were introduced, after checking that the key was made from seed by NewPrivKeyMT.
+func ImportLegacyPrivKeyMT(dat, seed []byte) (*PrivKeyMT, error) {
+	var s legacyPrivKeyMT
+	if err := decodeLegacy(dat, &s); err != nil {
+		return nil, err
+	}
+	p, err := s.privKeyMT(seed)
+	if err != nil {
+		for _, ms := range s.Merkle {
+			if ms != nil && ms.Priv != nil {
+				ms.Priv.Destroy()
+			}
+		}
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+//States in testdata/legacy were saved by the version before leaf ranges and MACs were introduced:
+//Merkle of height 4 made from legacyMerkleSeed after 1 signature,
+//and PrivKeyMT with h=20 and d=4 made from legacyMTSeed after 40 signatures.
+var (
+	legacyMerkleSeed = []byte("legacy seed of a Merkle")
+	legacyMTSeed     = []byte("legacy seed of a PrivKeyMT")
+)
+
+func readLegacy(t *testing.T, name string) []byte {
+	dat, err := ioutil.ReadFile(filepath.Join("testdata", "legacy", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dat
+}
+
+func TestImportLegacyMerkle(t *testing.T) {
+	pk := NewMerkle(4, legacyMerkleSeed).PublicKey()
+	msg := []byte("This is a test for legacy states.")
+	for _, name := range []string{"legacy_merkle.json", "legacy_merkle.msgpack"} {
+		dat := readLegacy(t, name)
+		var m Merkle
+		if name == "legacy_merkle.json" {
+			if err := json.Unmarshal(dat, &m); err == nil {
+				t.Error(name, "should be rejected by UnmarshalJSON")
+			}
+		} else if err := msgpack.Unmarshal(dat, &m); err == nil {
+			t.Error(name, "should be rejected by DecodeMsgpack")
+		}
+		if _, err := ImportLegacyMerkle(dat, legacyMTSeed); err == nil {
+			t.Error(name, "should be rejected with another seed")
+		}
+		mer, err := ImportLegacyMerkle(dat, legacyMerkleSeed)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if !bytes.Equal(mer.PublicKey(), pk) {
+			t.Error(name, "invalid public key")
+		}
+		if mer.LeafNo() != 1 || mer.Epoch() != 0 {
+			t.Error(name, "invalid leaf no or epoch", mer.LeafNo(), mer.Epoch())
+		}
+		if start, end := mer.Range(); start != 0 || end != 16 {
+			t.Error(name, "invalid range", start, end)
+		}
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if !Verify(sig, msg, pk) {
+			t.Error(name, "XMSS sig is incorrect")
+		}
+		dat2, err := json.Marshal(mer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ImportLegacyMerkle(dat2, legacyMerkleSeed); err == nil {
+			t.Error(name, "should not import a state with MAC as legacy")
+		}
+		var mer2 Merkle
+		if err := json.Unmarshal(dat2, &mer2); err != nil {
+			t.Fatal(name, err)
+		}
+		if mer2.LeafNo() != 2 {
+			t.Error(name, "invalid leaf no after saving", mer2.LeafNo())
+		}
+	}
+}
+
+func TestImportLegacyPrivKeyMT(t *testing.T) {
+	p, err := NewPrivKeyMT(legacyMTSeed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := p.PublicKey()
+	msg := []byte("This is a test for legacy states.")
+	for _, name := range []string{"legacy_mt.json", "legacy_mt.msgpack"} {
+		dat := readLegacy(t, name)
+		var pp PrivKeyMT
+		if name == "legacy_mt.json" {
+			if err := json.Unmarshal(dat, &pp); err == nil {
+				t.Error(name, "should be rejected by UnmarshalJSON")
+			}
+		} else if err := msgpack.Unmarshal(dat, &pp); err == nil {
+			t.Error(name, "should be rejected by DecodeMsgpack")
+		}
+		if _, err := ImportLegacyPrivKeyMT(dat, legacyMerkleSeed); err == nil {
+			t.Error(name, "should be rejected with another seed")
+		}
+		mt, err := ImportLegacyPrivKeyMT(dat, legacyMTSeed)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if !bytes.Equal(mt.PublicKey(), pk) {
+			t.Error(name, "invalid public key")
+		}
+		if mt.LeafNo() != 40 {
+			t.Error(name, "invalid index", mt.LeafNo())
+		}
+		if start, end := mt.Range(); start != 0 || end != 1<<20 {
+			t.Error(name, "invalid range", start, end)
+		}
+		for i := 0; i < 30; i++ {
+			sig, err := mt.Sign(msg)
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			if !VerifyMT(sig, msg, pk) {
+				t.Error(name, "XMSS^MT sig is incorrect")
+			}
+		}
+		dat2, err := json.Marshal(mt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mt2 PrivKeyMT
+		if err := json.Unmarshal(dat2, &mt2); err != nil {
+			t.Fatal(name, err)
+		}
+		if mt2.LeafNo() != 70 {
+			t.Error(name, "invalid index after saving", mt2.LeafNo())
+		}
+	}
+}
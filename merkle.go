@@ -25,8 +25,6 @@ import (
 	"encoding/json"
 	"errors"
 	"math"
-	"runtime"
-	"sync"
 
 	sha256 "github.com/AidosKuneen/sha256-simd"
 	"github.com/vmihailenco/msgpack"
@@ -52,10 +50,11 @@ func (nn *NH) exports() *nh {
 		Index:  nn.index,
 	}
 }
-func (nn *NH) imports(sr *nh) {
+func (nn *NH) imports(sr *nh) error {
 	nn.node = sr.Node
 	nn.height = sr.Height
 	nn.index = sr.Index
+	return nn.validate()
 }
 
 //MarshalJSON  marshals NH into valid JSON.
@@ -70,8 +69,7 @@ func (nn *NH) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	nn.imports(&sr)
-	return nil
+	return nn.imports(&sr)
 }
 
 //EncodeMsgpack  marshals NH into valid msgpack.
@@ -85,8 +83,7 @@ func (nn *NH) DecodeMsgpack(dec *msgpack.Decoder) error {
 	if err := dec.Decode(&sr); err != nil {
 		return err
 	}
-	nn.imports(&sr)
-	return nil
+	return nn.imports(&sr)
 }
 
 //Stack is a stack to use in merkle traversing.
@@ -96,6 +93,8 @@ type Stack struct {
 	leaf   uint32
 	layer  uint32
 	tree   uint64
+	//free holds nodes removed from stack to reuse them.
+	free []*NH
 }
 
 type stack struct {
@@ -116,12 +115,13 @@ func (s *Stack) exports() *stack {
 	}
 }
 
-func (s *Stack) imports(sr *stack) {
+func (s *Stack) imports(sr *stack) error {
 	s.stack = sr.Stack
 	s.height = sr.Height
 	s.leaf = sr.Leaf
 	s.layer = sr.Layer
 	s.tree = sr.Tree
+	return s.validate()
 }
 
 //MarshalJSON  marshals Stack into valid JSON.
@@ -136,8 +136,7 @@ func (s *Stack) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	s.imports(&sr)
-	return nil
+	return s.imports(&sr)
 }
 
 //EncodeMsgpack  marshals Stack into valid msgpack.
@@ -151,8 +150,7 @@ func (s *Stack) DecodeMsgpack(dec *msgpack.Decoder) error {
 	if err := dec.Decode(&sr); err != nil {
 		return err
 	}
-	s.imports(&sr)
-	return nil
+	return s.imports(&sr)
 }
 
 func (s *Stack) low() uint32 {
@@ -174,58 +172,82 @@ func (s *Stack) low() uint32 {
 func (s *Stack) initialize(start uint32, height uint32) {
 	s.leaf = start
 	s.height = height
-	s.stack = s.stack[:0]
+	s.delete(len(s.stack))
 }
 
 func (s *Stack) newleaf(priv *PrivKey, isGo bool) {
-	pk := make(wotsPubKey, wlen)
-	sk := make(wotsPrivKey, wlen)
-	for j := 0; j < wlen; j++ {
-		pk[j] = make([]byte, n)
-		sk[j] = make([]byte, n)
-	}
-	addrs := make(addr, 32)
+	h := getHasher()
+	pk, sk := h.pk, h.sk
+	addrs := h.addrs
+	copy(addrs, zero64[:32])
 
 	// addrs.set(adrType, 0)
 	addrs.set(adrLayer, s.layer)
 	addrs.setTree(s.tree)
 	addrs.set(adrOTS, s.leaf)
-	priv.newWotsPrivKey(addrs, sk)
+	h.newWotsPrivKey(priv, addrs, sk)
 	if isGo {
-		sk.goNewWotsPubKey(priv.pubPRF, addrs, pk)
+		sk.goNewWotsPubKey(priv.pubPRF, addrs, pk, priv.executor())
 	} else {
-		sk.newWotsPubKey(priv.pubPRF, addrs, pk)
+		h.lanes.chains(sk, chainStarts, chainSteps, priv.pubPRF, addrs, pk, 0, wlen)
+	}
+	for _, k := range sk {
+		wipe(k)
 	}
 	addrs.set(adrType, 1)
 	addrs.set(adrLtree, s.leaf)
-	nn := pk.ltree(priv.pubPRF, addrs)
-	node := &NH{
-		node:   make([]byte, 32),
-		height: 0,
-		index:  s.leaf,
-	}
+	nn := pk.ltreeWith(h.lanes, priv.pubPRF, addrs)
+	node := s.newNode(0, s.leaf)
 	copy(node.node, nn)
+	putHasher(h)
 	s.push(node)
 	s.leaf++
 }
 
+//nhBuf is NH with its node, to allocate them at once.
+type nhBuf struct {
+	NH
+	buf [n]byte
+}
+
+//newNH returns NH at height and index whose node is zeros.
+func newNH(height, index uint32) *NH {
+	b := &nhBuf{}
+	b.node = b.buf[:]
+	b.height = height
+	b.index = index
+	return &b.NH
+}
+
+//newNode returns NH at height and index whose node is zeros,
+//reusing a node removed from s if any.
+func (s *Stack) newNode(height, index uint32) *NH {
+	if len(s.free) == 0 {
+		return newNH(height, index)
+	}
+	nn := s.free[len(s.free)-1]
+	s.free[len(s.free)-1] = nil
+	s.free = s.free[:len(s.free)-1]
+	wipe(nn.node)
+	nn.height = height
+	nn.index = index
+	return nn
+}
+
 func (s *Stack) update(nn uint64, priv *PrivKey) {
-	s.updateSub(nn, priv, func() {
-		s.newleaf(priv, false)
-	})
+	s.updateSub(nn, priv, false)
 }
 
 func (s *Stack) goUpdate(nn uint64, priv *PrivKey) {
-	s.updateSub(nn, priv, func() {
-		s.newleaf(priv, true)
-	})
+	s.updateSub(nn, priv, true)
 }
 
-func (s *Stack) updateSub(nn uint64, priv *PrivKey, newleaf func()) {
+func (s *Stack) updateSub(nn uint64, priv *PrivKey, isGo bool) {
 	if len(s.stack) > 0 && (s.stack[len(s.stack)-1].height == s.height) {
 		return
 	}
-	addrs := make(addr, 32)
+	var ab [32]byte
+	addrs := addr(ab[:])
 	addrs.set(adrType, 2)
 	addrs.set(adrLayer, s.layer)
 	addrs.setTree(s.tree)
@@ -234,11 +256,7 @@ func (s *Stack) updateSub(nn uint64, priv *PrivKey, newleaf func()) {
 			right := s.top()
 			left := s.nextTop()
 			if left.height == right.height {
-				node := &NH{
-					node: make([]byte, 32),
-				}
-				node.index = right.index >> 1
-				node.height = right.height + 1
+				node := s.newNode(right.height+1, right.index>>1)
 				addrs.set(adrHeight, right.height)
 				addrs.set(adrIndex, node.index)
 				randHash(left.node, right.node, priv.pubPRF, addrs, node.node)
@@ -247,9 +265,17 @@ func (s *Stack) updateSub(nn uint64, priv *PrivKey, newleaf func()) {
 				continue
 			}
 		}
-		newleaf()
+		s.newleaf(priv, isGo)
+	}
+}
+
+//complete updates the stack until the node at its height is computed.
+func (s *Stack) complete(priv *PrivKey) {
+	for len(s.stack) == 0 || s.top().height != s.height {
+		s.goUpdate(1, priv)
 	}
 }
+
 func (s *Stack) top() *NH {
 	return s.stack[len(s.stack)-1]
 }
@@ -259,8 +285,12 @@ func (s *Stack) nextTop() *NH {
 func (s *Stack) push(n *NH) {
 	s.stack = append(s.stack, n)
 }
+
+//delete removes i nodes from the top of s and keeps them to reuse.
+//Nodes in s must not be referred from others.
 func (s *Stack) delete(i int) {
 	for j := 0; j < i; j++ {
+		s.free = append(s.free, s.stack[len(s.stack)-1-j])
 		s.stack[len(s.stack)-1-j] = nil
 	}
 	s.stack = s.stack[:len(s.stack)-i]
@@ -276,10 +306,46 @@ type Merkle struct {
 	priv   *PrivKey
 	layer  uint32
 	tree   uint64
+	//start and end are the range of leaves [start, end) which this Merkle is allowed to use.
+	start uint64
+	end   uint64
+	//tag is MAC of the range to detect tampering.
+	tag []byte
+	//checked is the range verified last, not to compute MAC for each signature.
+	checked rangeCheck
+	//k is the number of top levels whose nodes are all retained.
+	k uint32
+	//retain holds nodes in top k levels except the root.
+	retain [][]byte
+	//used is the bitmap of leaves used by SignAt if all nodes are retained.
+	used []byte
+	//async is true if traversing after Sign runs in background.
+	async bool
+	//done is closed when traversing in background finishes.
+	done chan struct{}
+	//tables holds precomputed WOTS+ chains of the next leaves if not nil.
+	tables *chainTables
+	//epoch increases whenever Leaf changes.
+	epoch uint64
+	//counter records the epoch outside if not nil.
+	counter Counter
+	//guard is true if signatures are verified before returning them.
+	guard bool
+	//suspect is true if a signature failed verification by the guard.
+	suspect bool
 }
 
 //NewMerkle makes Merkle struct from height and private seed.
 func NewMerkle(h byte, seed []byte) *Merkle {
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	m := newMerkle(uint32(h), wotsSeed, msgSeed, pubSeed, 0, 0, nil)
+	wipe(wotsSeed)
+	wipe(msgSeed)
+	return m
+}
+
+//deriveSeeds derives seeds for WOTS, message and public key from the private seed.
+func deriveSeeds(seed []byte) ([]byte, []byte, []byte) {
 	mac := hmac.New(sha256.New, seed)
 	if _, err := mac.Write([]byte{1}); err != nil {
 		panic(err)
@@ -295,83 +361,137 @@ func NewMerkle(h byte, seed []byte) *Merkle {
 		panic(err)
 	}
 	pubSeed := mac.Sum(nil)
-	return newMerkle(uint32(h), wotsSeed, msgSeed, pubSeed, 0, 0)
+	return wotsSeed, msgSeed, pubSeed
+}
+
+func newMerkle(h uint32, wotsSeed, msgSeed, pubSeed []byte, layer uint32, tree uint64, e Executor) *Merkle {
+	return newMerkleK(h, 0, wotsSeed, msgSeed, pubSeed, layer, tree, e)
 }
-func newMerkle(h uint32, wotsSeed, msgSeed, pubSeed []byte, layer uint32, tree uint64) *Merkle {
+
+//newMerkleK makes Merkle whose top k levels are retained.
+//Nodes are computed by e, or DefaultExecutor if e is nil.
+func newMerkleK(h, k uint32, wotsSeed, msgSeed, pubSeed []byte, layer uint32, tree uint64, e Executor) *Merkle {
+	m := allocMerkle(h, k, wotsSeed, msgSeed, pubSeed, layer, tree, e)
+
+	e = m.priv.executor()
+	//nodes at height lo are computed as roots of independent subtrees in parallel,
+	//and nodes above them are computed from them.
+	lo := subtreeHeight(h, e.Concurrency())
+	if lo > h-k {
+		lo = h - k
+	}
+	ntop := make([]*NH, 1<<(h-lo))
+	e.Run(len(ntop), func(i int) {
+		s := Stack{
+			stack:  make([]*NH, 0, lo+1),
+			height: lo,
+			leaf:   (1 << lo) * uint32(i),
+			layer:  m.layer,
+			tree:   m.tree,
+		}
+		if i != 0 {
+			s.update(1<<(lo+1)-1, m.priv)
+			ntop[i] = s.top()
+			return
+		}
+		//the first subtree records stacks and authes for leaf 0.
+		for j := uint32(0); j < lo; j++ {
+			s.update(1, m.priv)
+			m.stacks[j] = &Stack{
+				stack:  make([]*NH, 0, j+1),
+				height: j,
+				leaf:   1 << j,
+				layer:  m.layer,
+				tree:   m.tree,
+			}
+			m.stacks[j].push(s.top().clone())
+			s.update(1<<(j+1)-1, m.priv)
+			m.auth[j] = make([]byte, 32)
+			copy(m.auth[j], s.top().node)
+		}
+		s.update(1, m.priv)
+		ntop[0] = s.top()
+	})
+
+	addrs := make(addr, 32)
+	addrs.set(adrType, 2)
+	addrs.set(adrLayer, m.layer)
+	addrs.setTree(m.tree)
+	for i := lo; i < h; i++ {
+		if i < h-k {
+			m.stacks[i] = &Stack{
+				stack:  make([]*NH, 0, i+1),
+				height: i,
+				leaf:   1 << i,
+				layer:  m.layer,
+				tree:   m.tree,
+			}
+			m.stacks[i].push(ntop[0])
+		} else {
+			for j, nn := range ntop {
+				m.retain[retainIndex(h, k, i, uint32(j))] = nn.node
+			}
+		}
+		m.auth[i] = make([]byte, 32)
+		copy(m.auth[i], ntop[1].node)
+		upper := make([]*NH, len(ntop)/2)
+		for j := range upper {
+			upper[j] = &NH{
+				node:   make([]byte, 32),
+				height: i + 1,
+				index:  uint32(j),
+			}
+			addrs.set(adrHeight, i)
+			addrs.set(adrIndex, uint32(j))
+			randHash(ntop[2*j].node, ntop[2*j+1].node, m.priv.pubPRF, addrs, upper[j].node)
+		}
+		ntop = upper
+	}
+	copy(m.priv.root, ntop[0].node)
+	m.tag = m.rangeTag()
+	return m
+}
+
+//tasksPerWorker is the number of subtrees per worker when generating a tree.
+//Workers take subtrees one by one, so more subtrees keep all workers busy
+//until the end with any number of workers, at the cost of computing more nodes
+//above the subtrees serially.
+const tasksPerWorker = 8
+
+//subtreeHeight returns the height of subtrees which are computed in parallel
+//when generating a tree with height h by ncpu workers.
+func subtreeHeight(h uint32, ncpu int) uint32 {
+	if ncpu <= 1 {
+		return h
+	}
+	lo := h
+	for lo > 0 && 1<<(h-lo) < tasksPerWorker*ncpu {
+		lo--
+	}
+	return lo
+}
+
+//allocMerkle allocates Merkle whose nodes and root are not computed yet.
+func allocMerkle(h, k uint32, wotsSeed, msgSeed, pubSeed []byte, layer uint32, tree uint64, e Executor) *Merkle {
 	m := &Merkle{
 		Leaf:   0,
 		Height: h,
-		stacks: make([]*Stack, h),
+		stacks: make([]*Stack, h-k),
 		auth:   make([][]byte, h),
 		priv: &PrivKey{
 			wotsPRF: newPRF(wotsSeed),
 			pubPRF:  newPRF(pubSeed),
 			msgPRF:  newPRF(msgSeed),
 			root:    make([]byte, 32),
+			exec:    e,
 		},
-		layer: layer,
-		tree:  tree,
-	}
-
-	var wg sync.WaitGroup
-	ncpu := runtime.GOMAXPROCS(-1)
-	nproc := uint32(math.Log2(float64(ncpu)))
-	if ncpu != (1 << nproc) {
-		nproc++
-	}
-	if h <= nproc {
-		nproc = 0
-	}
-	ntop := make([]*NH, (1<<nproc)-1)
-	for i := uint32(1); i < (1 << nproc); i++ {
-		wg.Add(1)
-		go func(i uint32) {
-			s := Stack{
-				stack:  make([]*NH, 0, (h-nproc)+1),
-				height: h - nproc,
-				leaf:   (1 << (h - nproc)) * i,
-				layer:  m.layer,
-				tree:   m.tree,
-			}
-			s.update(1<<(h-nproc+1)-1, m.priv)
-			ntop[i-1] = s.top()
-			wg.Done()
-		}(i)
-	}
-	s := Stack{
-		stack:  make([]*NH, 0, h+1),
-		height: h,
-		leaf:   0,
-		layer:  m.layer,
-		tree:   m.tree,
-	}
-	for i := uint32(0); i < h; i++ {
-		if i == h-nproc {
-			wg.Wait()
-		}
-		s.update(1, m.priv)
-		m.stacks[i] = &Stack{
-			stack:  make([]*NH, 0, i+1),
-			height: i,
-			leaf:   1 << i,
-			layer:  m.layer,
-			tree:   m.tree,
-		}
-		m.stacks[i].push(s.top())
-		if i < h-nproc {
-			s.update(1<<(i+1)-1, m.priv)
-		} else {
-			s.updateSub(1<<(i-(h-nproc)+1)-1, m.priv, func() {
-				n := ntop[0]
-				ntop = ntop[1:]
-				s.push(n)
-			})
-		}
-		m.auth[i] = make([]byte, 32)
-		copy(m.auth[i], s.top().node)
+		layer:  layer,
+		tree:   tree,
+		end:    1 << h,
+		k:      k,
+		retain: make([][]byte, retainSize(k)),
 	}
-	s.update(1, m.priv)
-	copy(m.priv.root, s.top().node)
+	m.used = make([]byte, m.usedSize())
 	return m
 }
 
@@ -383,21 +503,65 @@ type merkle struct {
 	Stacks []*Stack
 	Layer  uint32
 	Tree   uint64
+	Start  uint64
+	End    uint64
+	Tag    []byte
+	K      uint32
+	Retain [][]byte
+	Used   []byte
+	Epoch  uint64
+	//Suspect is nil in states made before the flag was stored.
+	Suspect *bool
+	MAC     []byte
+	//version is the version of the binary encoding, or 0 if not decoded from binary.
+	version byte
+}
+
+//macVersion returns the binary version of the body which MAC of s covers.
+func (s *merkle) macVersion() byte {
+	switch {
+	case s.version != 0:
+		return s.version
+	case s.Suspect == nil:
+		return 2
+	}
+	return binaryVersion
 }
 
 func (m *Merkle) exports() *merkle {
+	suspect := m.suspect
 	return &merkle{
-		Leaf:   m.Leaf,
-		Height: m.Height,
-		Auth:   m.auth,
-		Priv:   m.priv,
-		Stacks: m.stacks,
-		Layer:  m.layer,
-		Tree:   m.tree,
+		Leaf:    m.Leaf,
+		Height:  m.Height,
+		Auth:    m.auth,
+		Priv:    m.priv,
+		Stacks:  m.stacks,
+		Layer:   m.layer,
+		Tree:    m.tree,
+		Start:   m.start,
+		End:     m.end,
+		Tag:     m.tag,
+		K:       m.k,
+		Retain:  m.retain,
+		Used:    m.used,
+		Epoch:   m.epoch,
+		Suspect: &suspect,
+		MAC:     m.mac(binaryVersion),
+	}
+}
+
+func (m *Merkle) imports(s *merkle) error {
+	if err := m.load(s); err != nil {
+		return err
+	}
+	if err := m.checkRange(); err != nil {
+		return err
 	}
+	return m.checkMAC(s.MAC, s.macVersion())
 }
 
-func (m *Merkle) imports(s *merkle) {
+//load sets s to m and validates it without checking the range tag and MAC.
+func (m *Merkle) load(s *merkle) error {
 	m.Leaf = s.Leaf
 	m.Height = s.Height
 	m.auth = s.Auth
@@ -405,36 +569,63 @@ func (m *Merkle) imports(s *merkle) {
 	m.stacks = s.Stacks
 	m.layer = s.Layer
 	m.tree = s.Tree
+	m.start = s.Start
+	m.end = s.End
+	m.tag = s.Tag
+	m.k = s.K
+	m.retain = s.Retain
+	m.used = s.Used
+	m.epoch = s.Epoch
+	m.suspect = s.Suspect != nil && *s.Suspect
+	if err := m.validate(); err != nil {
+		return err
+	}
+	if m.used == nil {
+		//made before SignAt was introduced, so no leaf above Leaf is used.
+		m.used = make([]byte, m.usedSize())
+	}
+	if len(m.used) != m.usedSize() {
+		return ValidationError("invalid size of the bitmap of used leaves")
+	}
+	return nil
 }
 
 //MarshalJSON  marshals Merkle into valid JSON.
 func (m *Merkle) MarshalJSON() ([]byte, error) {
+	m.Flush()
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
 	return json.Marshal(m.exports())
 }
 
 //UnmarshalJSON  unmarshals JSON to Merkle.
 func (m *Merkle) UnmarshalJSON(b []byte) error {
+	m.Flush()
 	var s merkle
-	err := json.Unmarshal(b, &s)
-	if err == nil {
-		m.imports(&s)
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
 	}
-	return err
+	return m.imports(&s)
 }
 
 //EncodeMsgpack  marshals Merkle into valid JSON.
 func (m *Merkle) EncodeMsgpack(enc *msgpack.Encoder) error {
+	m.Flush()
+	if m.priv.destroyed {
+		return ErrDestroyed
+	}
 	return enc.Encode(m.exports())
 }
 
 //DecodeMsgpack  unmarshals JSON to Merkle.
 func (m *Merkle) DecodeMsgpack(dec *msgpack.Decoder) error {
+	m.Flush()
 	var s merkle
-	err := dec.Decode(&s)
-	if err == nil {
-		m.imports(&s)
+	if err := dec.Decode(&s); err != nil {
+		return err
 	}
-	return err
+	return m.imports(&s)
 }
 
 //LeafNo returns the leaf no in merkle.
@@ -442,17 +633,106 @@ func (m *Merkle) LeafNo() uint64 {
 	return uint64(m.Leaf)
 }
 
-//SetLeafNo sets the leaf no in merkle and refresh authes..
+//SetLeafNo sets the leaf no in merkle and refresh authes.
+//Authes and stacks for the new leaf no are rebuilt directly from seeds,
+//which costs about one subtree computation per height instead of
+//traversing all leaves on the way.
 func (m *Merkle) SetLeafNo(n uint64) error {
-	if m.Leaf > uint32(n) {
+	m.Flush()
+	if m.priv.destroyed {
+		return ErrDestroyed
+	}
+	if uint64(m.Leaf) > n {
 		return errors.New("must not set past index")
 	}
-	for m.Leaf < uint32(n) {
-		m.Traverse()
+	if n > m.end {
+		return errors.New("index is out of range")
+	}
+	leaf := uint32(n)
+	t := uint32(len(m.stacks))
+	if (n-uint64(m.Leaf))*uint64(t) <= m.rebuildCost(leaf) {
+		//traversing is cheaper for short distances,
+		//because it computes about one leaf per height and step.
+		for m.Leaf < leaf {
+			m.Traverse()
+		}
+		m.refill()
+		return nil
 	}
+	for h := uint32(0); h < m.Height; h++ {
+		if h >= t {
+			if a := m.retained(h, (leaf>>h)^1); a != nil && m.Leaf>>h != leaf>>h {
+				m.auth[h] = a
+			}
+			continue
+		}
+		start := (((leaf >> h) + 1) ^ 1) << h
+		if m.Leaf>>h != leaf>>h {
+			if uint64(leaf) < 1<<m.Height {
+				m.auth[h] = m.treeNode(h, (leaf>>h)^1)
+			}
+			m.initStack(h, start)
+		} else if uint64(start) >= 1<<m.Height {
+			//stacks outside the tree may be left unfinished by older versions.
+			m.initStack(h, start)
+		}
+		m.stacks[h].complete(m.priv)
+	}
+	m.Leaf = leaf
+	m.epoch++
+	m.refill()
 	return nil
 }
 
+//rebuildCost returns the number of leaves computed to rebuild authes and stacks
+//for leaf from seeds in SetLeafNo.
+func (m *Merkle) rebuildCost(leaf uint32) uint64 {
+	var c uint64
+	for h := uint32(0); h < uint32(len(m.stacks)); h++ {
+		if m.Leaf>>h == leaf>>h {
+			continue
+		}
+		if uint64(leaf) < 1<<m.Height {
+			c += 1 << h
+		}
+		if uint64((((leaf>>h)+1)^1)<<h) < 1<<m.Height {
+			c += 1 << h
+		}
+	}
+	return c
+}
+
+//treeNode computes the node at height and index from seeds.
+func (m *Merkle) treeNode(height, index uint32) []byte {
+	s := &Stack{
+		stack:  make([]*NH, 0, height+1),
+		height: height,
+		leaf:   index << height,
+		layer:  m.layer,
+		tree:   m.tree,
+	}
+	s.complete(m.priv)
+	return s.top().node
+}
+
+//SetExecutor sets the Executor which runs tasks to build the tree and sign.
+//DefaultExecutor is used if e is nil.
+func (m *Merkle) SetExecutor(e Executor) {
+	m.Flush()
+	m.priv.exec = e
+}
+
+//Destroy wipes all secrets in Merkle.
+//Merkle cannot be used to sign after calling this.
+func (m *Merkle) Destroy() {
+	m.Flush()
+	if m.tables != nil {
+		m.tables.stop()
+		m.tables = nil
+	}
+	m.priv.Destroy()
+}
+
 //PublicKey returns public key (merkle root) of XMSS
 func (m *Merkle) PublicKey() []byte {
 	key := make([]byte, 1+n+n)
@@ -462,23 +742,51 @@ func (m *Merkle) PublicKey() []byte {
 	return key
 }
 
-func (m *Merkle) refreshAuth() {
+//refreshAuth updates authes and stacks after leaf is used.
+func (m *Merkle) refreshAuth(leaf uint32) {
 	var h uint32
 	for h = 0; h < m.Height; h++ {
 		var pow uint32 = 1 << h
-		if (m.Leaf+1)&(pow-1) == 0 {
-			m.auth[h] = m.stacks[h].top().node
-			startnode := ((m.Leaf + 1) + pow) ^ pow
-			m.stacks[h].initialize(startnode, h)
+		if h >= uint32(len(m.stacks)) {
+			a := m.retained(h, ((leaf+1)>>h)^1)
+			if a != nil && (leaf+1)&(pow-1) == 0 {
+				m.auth[h] = a
+			}
+			continue
+		}
+		if (leaf+1)&(pow-1) == 0 {
+			if s := m.stacks[h]; len(s.stack) == 0 || s.top().height != h {
+				//never happens with valid states.
+				s.complete(m.priv)
+			}
+			copy(m.auth[h], m.stacks[h].top().node)
+			startnode := ((leaf + 1) + pow) ^ pow
+			m.initStack(h, startnode)
 		}
 	}
 }
+
+//initStack initializes the stack at height h to compute the node whose first leaf is start.
+//A node outside the tree becomes an auth node only after the last leaf is used,
+//so its stack is finished at once with a zero node instead of computing leaves outside.
+func (m *Merkle) initStack(h, start uint32) {
+	s := m.stacks[h]
+	s.initialize(start, h)
+	if uint64(start) >= 1<<m.Height {
+		s.push(s.newNode(h, start>>h))
+		s.leaf += 1 << h
+	}
+}
 func (m *Merkle) build() {
+	t := uint32(len(m.stacks))
+	if t == 0 {
+		return
+	}
 	var i uint32
-	for i = 0; i < ((2 * m.Height) - 1); i++ {
+	for i = 0; i < ((2 * t) - 1); i++ {
 		var min uint32 = math.MaxUint32
 		var h, focus uint32
-		for h = 0; h < m.Height; h++ {
+		for h = 0; h < t; h++ {
 			low := m.stacks[h].low()
 			if low < min {
 				min = low
@@ -490,8 +798,14 @@ func (m *Merkle) build() {
 }
 
 //Traverse refreshes auth and stacks and increment leafe number.
+//It does nothing if Merkle is destroyed.
 func (m *Merkle) Traverse() {
-	m.refreshAuth()
+	m.Flush()
+	if m.priv.destroyed {
+		return
+	}
+	m.refreshAuth(m.Leaf)
 	m.build()
 	m.Leaf++
+	m.epoch++
 }
@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+//nextTree builds the Merkle tree which follows the current one in a layer of XMSS^MT
+//a few treehash updates at a time, so that signing doesn't need to build
+//the whole tree when the current one is used up.
+type nextTree struct {
+	m *Merkle
+	//s computes all nodes of the tree from left to right.
+	s *Stack
+	//count is the number of updates done.
+	count uint64
+}
+
+func newNextTree(h uint32, priv *PrivKey, layer uint32, tree uint64) *nextTree {
+	return &nextTree{
+		m: allocMerkle(h, 0, priv.wotsPRF.seed, priv.msgPRF.seed, priv.pubPRF.seed, layer, tree, priv.exec),
+		s: &Stack{
+			stack:  make([]*NH, 0, h+1),
+			height: h,
+			leaf:   0,
+			layer:  layer,
+			tree:   tree,
+		},
+	}
+}
+
+//remaining returns the number of updates needed to complete the tree.
+func (t *nextTree) remaining() uint64 {
+	return (1 << (t.m.Height + 1)) - 1 - t.count
+}
+
+func (t *nextTree) done() bool {
+	return t.remaining() == 0
+}
+
+//update runs nn treehash updates and records nodes which Merkle needs
+//to start traversing from leaf 0.
+func (t *nextTree) update(nn uint64, parallel bool) {
+	for i := uint64(0); i < nn && !t.done(); i++ {
+		if parallel {
+			t.s.goUpdate(1, t.m.priv)
+		} else {
+			t.s.update(1, t.m.priv)
+		}
+		t.count++
+		t.record(t.s.top())
+	}
+}
+
+func (t *nextTree) record(nn *NH) {
+	m := t.m
+	switch {
+	case nn.height == m.Height:
+		copy(m.priv.root, nn.node)
+		m.tag = m.rangeTag()
+		return
+	case nn.height >= m.Height-m.k:
+		m.retain[retainIndex(m.Height, m.k, nn.height, nn.index)] = nn.clone().node
+	case nn.index == 0:
+		m.stacks[nn.height] = &Stack{
+			stack:  make([]*NH, 0, nn.height+1),
+			height: nn.height,
+			leaf:   1 << nn.height,
+			layer:  m.layer,
+			tree:   m.tree,
+		}
+		m.stacks[nn.height].push(nn.clone())
+	}
+	if nn.index == 1 {
+		m.auth[nn.height] = make([]byte, 32)
+		copy(m.auth[nn.height], nn.node)
+	}
+}
+
+//finish completes the tree and returns it.
+func (t *nextTree) finish() *Merkle {
+	t.update(t.remaining(), true)
+	return t.m
+}
+
+//buildNext advances builders of the next trees in all layers but the top,
+//spreading the remaining work evenly over the signatures left before
+//each next tree is needed.
+func (p *PrivKeyMT) buildNext() {
+	hh := p.h / p.d
+	for j := uint32(0); j+1 < p.d; j++ {
+		cur := p.merkle[j]
+		if cur == nil {
+			continue
+		}
+		tree := cur.tree + 1
+		if tree >= 1<<(p.h-hh*(j+1)) {
+			continue
+		}
+		start := tree << (hh * (j + 1))
+		if start <= p.index {
+			//refresh will finish it.
+			continue
+		}
+		b := p.next[j]
+		if b == nil || b.m.tree != tree {
+			if b != nil {
+				b.m.Destroy()
+			}
+			b = newNextTree(hh, p.merkle[p.d-1].priv, j, tree)
+			p.next[j] = b
+		}
+		left := start - p.index
+		b.update((b.remaining()+left-1)/left, false)
+	}
+}
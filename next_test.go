@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestNextTree(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for building next trees.")
+	for i := 0; i < 31; i++ {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMT(sig, msg, mer.PublicKey()) {
+			t.Error("XMSS^MT sig is incorrect", i)
+		}
+	}
+	b := mer.next[0]
+	if b == nil || b.m.tree != 1 || !b.done() {
+		t.Fatal("next tree in layer 0 must be built")
+	}
+	for j := 1; j < 3; j++ {
+		if mer.next[j] == nil || mer.next[j].count == 0 {
+			t.Error("next tree must be being built", j)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMT(sig, msg, mer.PublicKey()) {
+			t.Error("XMSS^MT sig is incorrect", i)
+		}
+	}
+	if mer.merkle[0] != b.m {
+		t.Error("next tree must be used")
+	}
+
+	priv := mer.merkle[3].priv
+	m := newMerkle(5, priv.wotsPRF.seed, priv.msgPRF.seed, priv.pubPRF.seed, 0, 1, nil)
+	//the next tree signed at leaf 0.
+	m.Traverse()
+	dat1, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dat2, err := json.Marshal(b.m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dat1, dat2) {
+		t.Error("next tree is incorrect")
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//A node cache holds all nodes of trees to restore Merkle or PrivKeyMT
+//without computing leaves again.
+//A tree in the cache is encoded as:
+//
+//	magic ("xmss-nodes", 10 bytes) | version (1 byte) | height (1 byte) |
+//	layer (4 bytes) | tree (8 bytes) | root (32 bytes) | public seed (32 bytes) |
+//	nodes (32 bytes each) | HMAC-SHA256 (32 bytes)
+//
+//Nodes are ordered from height 0 (leaves) to height-1, and by index in each height.
+//HMAC is keyed with the secret seed of the key and covers all bytes before it,
+//so the cache cannot be modified or used for other keys.
+//A cache of PrivKeyMT is the number of trees (4 bytes) followed by the trees.
+//Integers are in big endian.
+
+var nodesMagic = []byte("xmss-nodes")
+
+const (
+	nodesVersion = 1
+	//nodesMaxHeight is the max height of trees in the cache (2 GB of nodes).
+	nodesMaxHeight  = 25
+	nodesHeaderSize = 10 + 1 + 1 + 4 + 8 + n + n
+)
+
+//treeNodes is a tree read from a node cache.
+type treeNodes struct {
+	height uint32
+	layer  uint32
+	tree   uint64
+	root   []byte
+	nodes  []byte
+}
+
+//node returns a copy of the node at height and index.
+func (t *treeNodes) node(height, index uint32) []byte {
+	off := retainIndex(t.height, t.height, height, index) * n
+	nn := make([]byte, n)
+	copy(nn, t.nodes[off:])
+	return nn
+}
+
+func nodesMAC(wotsSeed []byte) hash.Hash {
+	mac := hmac.New(sha256.New, wotsSeed)
+	if _, err := mac.Write([]byte("xmss node cache")); err != nil {
+		panic(err)
+	}
+	return mac
+}
+
+//ExportNodes writes all nodes of the tree of m to w,
+//to restore m quickly by ImportMerkle later.
+//It computes the whole tree again unless m retains all nodes (see NewMerkleFull).
+func (m *Merkle) ExportNodes(w io.Writer) error {
+	m.Flush()
+	if m.priv.destroyed {
+		return ErrDestroyed
+	}
+	return m.exportNodes(w)
+}
+
+func (m *Merkle) exportNodes(w io.Writer) error {
+	nodes := m.retain
+	if !m.full() {
+		mm := newMerkleK(m.Height, m.Height, m.priv.wotsPRF.seed, m.priv.msgPRF.seed, m.priv.pubPRF.seed, m.layer, m.tree, m.priv.exec)
+		nodes = mm.retain
+		mm.Destroy()
+	}
+	mac := nodesMAC(m.priv.wotsPRF.seed)
+	mw := io.MultiWriter(w, mac)
+	header := make([]byte, nodesHeaderSize)
+	copy(header, nodesMagic)
+	header[10] = nodesVersion
+	header[11] = byte(m.Height)
+	binary.BigEndian.PutUint32(header[12:], m.layer)
+	binary.BigEndian.PutUint64(header[16:], m.tree)
+	copy(header[24:], m.priv.root)
+	copy(header[24+n:], m.priv.pubPRF.seed)
+	if _, err := mw.Write(header); err != nil {
+		return err
+	}
+	buf := make([]byte, 0, 1024*n)
+	for _, nn := range nodes {
+		buf = append(buf, nn...)
+		if len(buf) == cap(buf) {
+			if _, err := mw.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	if _, err := mw.Write(buf); err != nil {
+		return err
+	}
+	_, err := w.Write(mac.Sum(nil))
+	return err
+}
+
+//readNodes reads a tree from r and checks its MAC and root.
+//If height is not 0, a tree of another height is rejected before reading its nodes.
+//Nodes are read into a buffer growing with the bytes actually read,
+//so that a forged header cannot make it allocate the whole size before MAC is checked.
+func readNodes(r io.Reader, wotsSeed, pubSeed []byte, height uint32) (*treeNodes, error) {
+	mac := nodesMAC(wotsSeed)
+	tr := io.TeeReader(r, mac)
+	header := make([]byte, nodesHeaderSize)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:10], nodesMagic) {
+		return nil, ValidationError("not a node cache")
+	}
+	if header[10] != nodesVersion {
+		return nil, ValidationError("unsupported version of node cache")
+	}
+	t := &treeNodes{
+		height: uint32(header[11]),
+		layer:  binary.BigEndian.Uint32(header[12:]),
+		tree:   binary.BigEndian.Uint64(header[16:]),
+		root:   header[24 : 24+n],
+	}
+	if t.height == 0 || t.height > nodesMaxHeight {
+		return nil, ValidationError("invalid height of node cache")
+	}
+	if height != 0 && t.height != height {
+		return nil, ValidationError("node cache is not for the parameters")
+	}
+	if !bytes.Equal(header[24+n:], pubSeed) {
+		return nil, ValidationError("node cache is for another key")
+	}
+	size := int64(retainSize(t.height) * n)
+	nodes, err := ioutil.ReadAll(io.LimitReader(tr, size))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(nodes)) != size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	t.nodes = nodes
+	tag := make([]byte, n)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(tag, mac.Sum(nil)) {
+		return nil, ValidationError("node cache was tampered or is for another key")
+	}
+	addrs := make(addr, 32)
+	addrs.set(adrType, 2)
+	addrs.set(adrLayer, t.layer)
+	addrs.setTree(t.tree)
+	addrs.set(adrHeight, t.height-1)
+	root := make([]byte, n)
+	randHash(t.node(t.height-1, 0), t.node(t.height-1, 1), newPRF(pubSeed), addrs, root)
+	if !bytes.Equal(root, t.root) {
+		return nil, ValidationError("invalid root of node cache")
+	}
+	return t, nil
+}
+
+//loadMerkle makes Merkle at leaf from nodes in t without computing leaves.
+func loadMerkle(t *treeNodes, k uint32, wotsSeed, msgSeed, pubSeed []byte, leaf uint32, e Executor) *Merkle {
+	h := t.height
+	m := allocMerkle(h, k, wotsSeed, msgSeed, pubSeed, t.layer, t.tree, e)
+	copy(m.priv.root, t.root)
+	for i := uint32(0); i < h; i++ {
+		m.auth[i] = t.node(i, (leaf>>i)^1)
+		if i >= h-k {
+			for j := uint32(0); j < 1<<(h-i); j++ {
+				m.retain[retainIndex(h, k, i, j)] = t.node(i, j)
+			}
+			continue
+		}
+		//same as the state after SetLeafNo(leaf).
+		m.stacks[i] = &Stack{
+			stack:  make([]*NH, 0, i+1),
+			height: i,
+			layer:  t.layer,
+			tree:   t.tree,
+		}
+		idx := ((leaf >> i) + 1) ^ 1
+		m.initStack(i, idx<<i)
+		if uint64(idx) < 1<<(h-i) {
+			nn := newNH(i, idx)
+			copy(nn.node, t.node(i, idx))
+			m.stacks[i].push(nn)
+			m.stacks[i].leaf = (idx + 1) << i
+		}
+	}
+	m.Leaf = leaf
+	m.tag = m.rangeTag()
+	return m
+}
+
+//ImportMerkle restores Merkle made by NewMerkleK(h, k, seed) (or NewMerkle if k is 0)
+//at leaf from the node cache written by ExportNodes, without computing leaves.
+//The returned Merkle owns all leaves, so leaf must be the first unused one.
+func ImportMerkle(r io.Reader, seed []byte, k byte, leaf uint64) (*Merkle, error) {
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	defer wipe(wotsSeed)
+	defer wipe(msgSeed)
+	t, err := readNodes(r, wotsSeed, pubSeed, 0)
+	if err != nil {
+		return nil, err
+	}
+	if t.layer != 0 || t.tree != 0 {
+		return nil, errors.New("node cache is not for XMSS")
+	}
+	if uint32(k) > t.height {
+		return nil, errors.New("k must not be larger than height")
+	}
+	if leaf >= 1<<t.height {
+		return nil, errors.New("index is out of range")
+	}
+	return loadMerkle(t, uint32(k), wotsSeed, msgSeed, pubSeed, uint32(leaf), nil), nil
+}
+
+//ExportNodes writes all nodes of the current trees in all layers of p to w,
+//to restore p quickly by ImportPrivKeyMT later.
+//It computes all trees again.
+func (p *PrivKeyMT) ExportNodes(w io.Writer) error {
+	if p.destroyed() {
+		return ErrDestroyed
+	}
+	var num uint32
+	for _, m := range p.merkle {
+		if m != nil {
+			num++
+		}
+	}
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, num)
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	for _, m := range p.merkle {
+		if m == nil {
+			continue
+		}
+		if err := m.exportNodes(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ImportPrivKeyMT restores PrivKeyMT made by NewPrivKeyMT(seed, h, d) at index
+//from the node cache written by ExportNodes.
+//Trees which are not in the cache (i.e. lower trees for a distant index) are computed.
+//The returned key owns all indices, so index must be the first unused one.
+func ImportPrivKeyMT(r io.Reader, seed []byte, h, d uint32, index uint64) (*PrivKeyMT, error) {
+	if _, err := PublickeyMTHeader(h, d); err != nil {
+		return nil, err
+	}
+	if index >= numIndices(h) {
+		return nil, errors.New("index is out of range")
+	}
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	defer wipe(wotsSeed)
+	defer wipe(msgSeed)
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	num := binary.BigEndian.Uint32(b)
+	if num > d {
+		return nil, ValidationError("too many trees in node cache")
+	}
+	type treeID struct {
+		layer uint32
+		tree  uint64
+	}
+	trees := make(map[treeID]*treeNodes, num)
+	for i := uint32(0); i < num; i++ {
+		t, err := readNodes(r, wotsSeed, pubSeed, h/d)
+		if err != nil {
+			return nil, err
+		}
+		if t.layer >= d {
+			return nil, ValidationError("node cache is not for the parameters")
+		}
+		trees[treeID{t.layer, t.tree}] = t
+	}
+	p := &PrivKeyMT{
+		merkle: make([]*Merkle, d),
+		h:      h,
+		d:      d,
+		end:    numIndices(h),
+		index:  index,
+	}
+	mask := uint64((1 << (h / d)) - 1)
+	idxTree := index
+	for j := uint32(0); j < d; j++ {
+		idxLeaf := uint32(idxTree & mask)
+		idxTree >>= h / d
+		if t, ok := trees[treeID{j, idxTree}]; ok {
+			p.merkle[j] = loadMerkle(t, 0, wotsSeed, msgSeed, pubSeed, idxLeaf, nil)
+			continue
+		}
+		p.merkle[j] = newMerkle(h/d, wotsSeed, msgSeed, pubSeed, j, idxTree, nil)
+		if err := p.merkle[j].SetLeafNo(uint64(idxLeaf)); err != nil {
+			return nil, err
+		}
+	}
+	p.tag = p.rangeTag()
+	return p, nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func TestNodes(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for node caches.")
+	mer := NewMerkle(6, seed)
+	var buf bytes.Buffer
+	if err := mer.ExportNodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	cache := buf.Bytes()
+	fmer, err := NewMerkleFull(6, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := fmer.ExportNodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cache, buf.Bytes()) {
+		t.Error("node caches differ")
+	}
+
+	for _, k := range []byte{0, 2, 6} {
+		for _, leaf := range []uint64{0, 40, 63} {
+			mer, err := NewMerkleK(6, k, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := mer.SetLeafNo(leaf); err != nil {
+				t.Fatal(err)
+			}
+			imer, err := ImportMerkle(bytes.NewReader(cache), seed, k, leaf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dat, err := json.Marshal(mer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			//the restored state starts a new epoch.
+			imer.epoch = mer.epoch
+			idat, err := json.Marshal(imer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dat, idat) {
+				t.Error("states differ", k, leaf)
+			}
+			sig, err := imer.Sign(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Verify(sig, msg, mer.PublicKey()) {
+				t.Error("signature is invalid", k, leaf)
+			}
+		}
+	}
+
+	if _, err := ImportMerkle(bytes.NewReader(cache), generateSeed(), 0, 0); err == nil {
+		t.Error("should not import a cache of another key")
+	}
+	if _, err := ImportMerkle(bytes.NewReader(cache), seed, 0, 64); err == nil {
+		t.Error("should not import out of range")
+	}
+	if _, err := ImportMerkle(bytes.NewReader(cache[:len(cache)-1]), seed, 0, 0); err == nil {
+		t.Error("should not import a truncated cache")
+	}
+	for _, i := range []int{0, 11, nodesHeaderSize + 100, len(cache) - 1} {
+		bad := make([]byte, len(cache))
+		copy(bad, cache)
+		bad[i] ^= 1
+		if _, err := ImportMerkle(bytes.NewReader(bad), seed, 0, 0); err == nil {
+			t.Error("should not import a tampered cache", i)
+		}
+	}
+}
+
+func TestNodesForgedHeight(t *testing.T) {
+	seed := generateSeed()
+	mer := NewMerkle(4, seed)
+	var buf bytes.Buffer
+	if err := mer.ExportNodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	//a header claiming 2^26 nodes followed by a few bytes.
+	forged := buf.Bytes()[:nodesHeaderSize+64]
+	forged[11] = nodesMaxHeight
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := ImportMerkle(bytes.NewReader(forged), seed, 0, 0); err == nil {
+		t.Error("should not import a forged cache")
+	}
+	runtime.ReadMemStats(&after)
+	if a := after.TotalAlloc - before.TotalAlloc; a > 1<<20 {
+		t.Error("a forged header must not make it allocate the claimed size", a)
+	}
+
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := mt.ExportNodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	forged = buf.Bytes()[:4+nodesHeaderSize+64]
+	forged[4+11] = nodesMaxHeight
+	if _, err := ImportPrivKeyMT(bytes.NewReader(forged), seed, 20, 4, 0); err != ValidationError("node cache is not for the parameters") {
+		t.Error("should reject a tree of another height before reading nodes", err)
+	}
+}
+
+func TestNodesNearEnd(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for node caches near the end.")
+	mer := NewMerkle(10, seed)
+	var buf bytes.Buffer
+	if err := mer.ExportNodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	cache := buf.Bytes()
+	wotsSeed, msgSeed, pubSeed := deriveSeeds(seed)
+	tn, err := readNodes(bytes.NewReader(cache), wotsSeed, pubSeed, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const leaf = 1<<10 - 4
+	e := &countExecutor{}
+	imer := loadMerkle(tn, 0, wotsSeed, msgSeed, pubSeed, leaf, e)
+	if e.runs != 0 {
+		t.Error("leaves are computed to import", e.runs)
+	}
+	//stacks outside the tree are loaded finished, so traversing to the end
+	//computes at most a leaf per step.
+	for i := leaf; i < 1<<10; i++ {
+		imer.Traverse()
+	}
+	if e.runs > 1<<10-leaf {
+		t.Error("too many leaves are computed", e.runs)
+	}
+	imer, err = ImportMerkle(bytes.NewReader(cache), seed, 0, leaf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := leaf; i < 1<<10; i++ {
+		sig, err := imer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(sig, msg, mer.PublicKey()) {
+			t.Error("signature is invalid", i)
+		}
+	}
+}
+
+func TestNodesMT(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for node caches of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mt.SetLeafNo(3000); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := mt.ExportNodes(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint64{3000, 3100, 9000} {
+		mt2, err := NewPrivKeyMT(seed, 20, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mt2.SetLeafNo(index); err != nil {
+			t.Fatal(err)
+		}
+		imt, err := ImportPrivKeyMT(bytes.NewReader(buf.Bytes()), seed, 20, 2, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := mt2.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isig, err := imt.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, isig) {
+			t.Error("signatures differ", index)
+		}
+		if !VerifyMT(isig, msg, mt.PublicKey()) {
+			t.Error("signature is invalid", index)
+		}
+	}
+	if _, err := ImportPrivKeyMT(bytes.NewReader(buf.Bytes()), seed, 20, 4, 0); err == nil {
+		t.Error("should not import a cache with other parameters")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import "sync"
+
+//tableSize is the size of a chain table of a leaf,
+//all values in all WOTS+ chains.
+const tableSize = wlen * w * n
+
+//chainTables precomputes chain tables of the next leaves in background,
+//so that signing becomes table lookups.
+type chainTables struct {
+	mu sync.Mutex
+	//depth is the number of leaves to be precomputed.
+	depth uint32
+	//next is the leaf to be signed next, and end is the end of the range of leaves.
+	next uint64
+	end  uint64
+	tabs map[uint32][]byte
+	//running is true while the worker is running.
+	running bool
+	wg      sync.WaitGroup
+}
+
+//SetPrecompute makes m precompute all values of WOTS+ chains
+//for the next depth leaves in background (about 34 KB per leaf),
+//so that Sign only looks up the tables and needs no hashing for WOTS+.
+//Tables are wiped after use, and by Destroy.
+//depth 0 stops precomputing and wipes the tables.
+func (m *Merkle) SetPrecompute(depth uint32) {
+	m.Flush()
+	if m.tables != nil {
+		m.tables.stop()
+		m.tables = nil
+	}
+	if depth == 0 || m.priv.destroyed {
+		return
+	}
+	m.tables = &chainTables{
+		depth: depth,
+		tabs:  make(map[uint32][]byte, depth),
+	}
+	m.tables.fill(m, uint64(m.Leaf))
+}
+
+//fill starts the worker to compute tables for leaves from next.
+func (c *chainTables) fill(m *Merkle, next uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next = next
+	c.end = m.end
+	for leaf, tab := range c.tabs {
+		if uint64(leaf) < next {
+			wipe(tab)
+			delete(c.tabs, leaf)
+		}
+	}
+	if c.running {
+		return
+	}
+	c.running = true
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			leaf, ok := c.want()
+			if !ok {
+				return
+			}
+			tab := m.chainTable(leaf)
+			c.mu.Lock()
+			if uint64(leaf) >= c.next && c.running {
+				c.tabs[leaf] = tab
+			} else {
+				wipe(tab)
+			}
+			c.mu.Unlock()
+		}
+	}()
+}
+
+//want returns the leaf whose table should be computed next.
+//It stops the worker if no table is needed.
+func (c *chainTables) want() (uint32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.running {
+		return 0, false
+	}
+	for l := c.next; l < c.next+uint64(c.depth) && l < c.end; l++ {
+		if _, ok := c.tabs[uint32(l)]; !ok {
+			return uint32(l), true
+		}
+	}
+	c.running = false
+	return 0, false
+}
+
+//take returns the table of leaf and removes it from c, or nil if not computed.
+func (c *chainTables) take(leaf uint32) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tab := c.tabs[leaf]
+	delete(c.tabs, leaf)
+	return tab
+}
+
+//stop stops the worker and wipes all tables.
+func (c *chainTables) stop() {
+	c.mu.Lock()
+	c.running = false
+	c.mu.Unlock()
+	c.wg.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for leaf, tab := range c.tabs {
+		wipe(tab)
+		delete(c.tabs, leaf)
+	}
+}
+
+//chainTable computes all values of WOTS+ chains at leaf.
+//Value j of chain i is at (i*w+j)*n.
+func (m *Merkle) chainTable(leaf uint32) []byte {
+	tab := make([]byte, tableSize)
+	h := getHasher()
+	addrs := h.addrs
+	copy(addrs, zero64[:32])
+	addrs.set(adrLayer, m.layer)
+	addrs.setTree(m.tree)
+	addrs.set(adrOTS, leaf)
+	h.newWotsPrivKey(m.priv, addrs, h.sk)
+	for i, sk := range h.sk {
+		addrs.set(adrChain, uint32(i))
+		v := tab[i*w*n:]
+		copy(v, sk)
+		wipe(sk)
+		for j := 0; j < w-1; j++ {
+			h.chain(v[j*n:(j+1)*n], byte(j), 1, m.priv.pubPRF, addrs, v[(j+1)*n:(j+2)*n])
+		}
+	}
+	putHasher(h)
+	return tab
+}
+
+//signByTable signs hmsg with the precomputed table of leaf into out,
+//or returns false if the table is not computed.
+func (m *Merkle) signByTable(leaf uint32, hmsg []byte, out wotsSig) bool {
+	if m.tables == nil {
+		return false
+	}
+	tab := m.tables.take(leaf)
+	if tab == nil {
+		return false
+	}
+	var msg [wlen]byte
+	baseW(hmsg, msg[:])
+	for i := range out {
+		copy(out[i], tab[(i*w+int(msg[i]))*n:])
+	}
+	wipe(tab)
+	return true
+}
+
+//refill makes the worker precompute tables from the current leaf if precomputing is on.
+func (m *Merkle) refill() {
+	if m.tables != nil {
+		m.tables.fill(m, uint64(m.Leaf))
+	}
+}
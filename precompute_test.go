@@ -0,0 +1,104 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"testing"
+)
+
+func (c *chainTables) len() int {
+	c.wg.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tabs)
+}
+
+func TestPrecompute(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for precomputed chains.")
+	mer := NewMerkle(5, seed)
+	pmer := NewMerkle(5, seed)
+	pmer.SetPrecompute(4)
+	if l := pmer.tables.len(); l != 4 {
+		t.Error("tables must be precomputed", l)
+	}
+	for i := 0; i < 1<<5; i++ {
+		if i == 16 {
+			pmer.SetAsync(true)
+		}
+		if i < 1<<5-4 {
+			if l := pmer.tables.len(); l != 4 {
+				t.Error("tables must be precomputed", i, l)
+			}
+		}
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		psig, err := pmer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, psig) {
+			t.Fatal("signatures differ", i)
+		}
+	}
+	if l := pmer.tables.len(); l != 0 {
+		t.Error("tables must not be computed out of range", l)
+	}
+
+	pmer = NewMerkle(5, seed)
+	pmer.SetPrecompute(3)
+	pmer.tables.len()
+	if err := pmer.SetLeafNo(20); err != nil {
+		t.Fatal(err)
+	}
+	if l := pmer.tables.len(); l != 3 {
+		t.Error("tables must be precomputed", l)
+	}
+	if _, ok := pmer.tables.tabs[2]; ok {
+		t.Error("tables of skipped leaves must be wiped")
+	}
+	tab := pmer.tables.tabs[20]
+	pmer.Destroy()
+	if pmer.tables != nil || !bytes.Equal(tab, make([]byte, tableSize)) {
+		t.Error("tables must be wiped")
+	}
+
+	pmer = NewMerkle(5, seed)
+	pmer.SetPrecompute(2)
+	pmer.SetPrecompute(0)
+	if pmer.tables != nil {
+		t.Error("precomputing must be stopped")
+	}
+	if !Verify(mustSign(t, pmer, msg), msg, pmer.PublicKey()) {
+		t.Error("signature is invalid")
+	}
+}
+
+func mustSign(t *testing.T, m *Merkle, msg []byte) []byte {
+	sig, err := m.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/binary"
+	"sync"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//hasher holds scratch buffers for hashing, so that hot paths don't allocate.
+//A hasher is used by one goroutine at a time. Get one by getHasher and
+//return it by putHasher after use.
+type hasher struct {
+	stat  []uint32
+	buf   []byte
+	key   []byte
+	bm0   []byte
+	bm1   []byte
+	addrs addr
+	lanes *hashLanes
+	//wprf is PRF whose seed is changed for each WOTS+ private key.
+	wprf *prf
+	//sk and pk are WOTS+ private and public keys being computed.
+	sk wotsPrivKey
+	pk wotsPubKey
+	//digits and steps are base w digits of a message and steps of chains for it.
+	digits []byte
+	steps  []byte
+	//body refers to a signature being signed or verified.
+	body *xmssSigBody
+}
+
+var hasherPool = sync.Pool{
+	New: func() interface{} {
+		return newHasher()
+	},
+}
+
+func newHasher() *hasher {
+	b := make([]byte, 64+32*4)
+	h := &hasher{
+		stat:  make([]uint32, 8),
+		buf:   b[:64],
+		key:   b[64:96],
+		bm0:   b[96:128],
+		bm1:   b[128:160],
+		addrs: addr(b[160:192]),
+		lanes: newHashLanes(),
+		wprf: &prf{
+			seed:   make([]byte, 32),
+			block1: make([]uint32, 8),
+		},
+		sk:     make(wotsPrivKey, wlen),
+		pk:     make(wotsPubKey, wlen),
+		digits: make([]byte, wlen),
+		steps:  make([]byte, wlen),
+		body: &xmssSigBody{
+			sig:  make(wotsSig, wlen),
+			auth: make([][]byte, 0, maxHeight),
+		},
+	}
+	keys := make([]byte, 2*wlen*n)
+	for i := 0; i < wlen; i++ {
+		h.sk[i] = keys[i*n : (i+1)*n]
+		h.pk[i] = keys[(wlen+i)*n : (wlen+i+1)*n]
+	}
+	return h
+}
+
+func getHasher() *hasher {
+	return hasherPool.Get().(*hasher)
+}
+
+//putHasher wipes small buffers in h, which may hold secrets, and returns h to the pool.
+//Users of sk, pk, prf and lanes must wipe them by themselves.
+func putHasher(h *hasher) {
+	for i := range h.stat {
+		h.stat[i] = 0
+	}
+	wipe(h.buf)
+	wipe(h.key)
+	wipe(h.bm0)
+	wipe(h.bm1)
+	hasherPool.Put(h)
+}
+
+//sigBody returns the signature body in b with the auth path of height,
+//whose chains and nodes refer to b.
+//Call clearBody after use not to keep b in the pool.
+func (h *hasher) sigBody(b []byte, height int) *xmssSigBody {
+	body := h.body
+	for i := range body.sig {
+		body.sig[i] = b[i*n : (i+1)*n]
+	}
+	body.auth = body.auth[:height]
+	for i := range body.auth {
+		body.auth[i] = b[(wlen+i)*n : (wlen+i+1)*n]
+	}
+	return body
+}
+
+func (h *hasher) clearBody() {
+	for i := range h.body.sig {
+		h.body.sig[i] = nil
+	}
+	for i := range h.body.auth {
+		h.body.auth[i] = nil
+	}
+	h.body.auth = h.body.auth[:0]
+}
+
+//hashMsg computes H_msg of m with key (96 bytes) into out.
+//m is copied block by block, so that it doesn't escape.
+func (h *hasher) hashMsg(key, m, out []byte) {
+	l := uint64(len(m)) + 128
+	copy(h.stat, sha256Init)
+	buf := h.buf
+	copy(buf, zero64[:32])
+	buf[31] = 0x2
+	copy(buf[32:], key[:32])
+	sha256.Block(h.stat, buf)
+	copy(buf, key[32:96])
+	sha256.Block(h.stat, buf)
+	for ; len(m) >= 64; m = m[64:] {
+		copy(buf, m[:64])
+		sha256.Block(h.stat, buf)
+	}
+	copy(buf, zero64)
+	copy(buf, m)
+	buf[len(m)] = 0x80
+	if len(m) >= 56 {
+		sha256.Block(h.stat, buf)
+		copy(buf, zero64)
+	}
+	binary.BigEndian.PutUint64(buf[56:], l<<3)
+	sha256.Block(h.stat, buf)
+	sha256.Int2Bytes(h.stat, out)
+}
+
+//key:32bytes, m:32bytes
+func (h *hasher) hashF(key, m, out []byte) {
+	copy(h.stat, sha256Init)
+	buf := h.buf
+	copy(buf, zero64[:32])
+	copy(buf[32:], key)
+	sha256.Block(h.stat, buf)
+	copy(buf, m)
+	copy(buf[32:], zero64)
+	buf[32] = 0x80
+	buf[62] = 0x03
+	// buf[63] = 0x00
+	sha256.Block(h.stat, buf)
+	sha256.Int2Bytes(h.stat, out)
+}
+
+//key:32bytes, m:64bytes
+func (h *hasher) hashH(key, m1, m2, out []byte) {
+	copy(h.stat, sha256Init)
+	buf := h.buf
+	copy(buf, zero64[:32])
+	buf[31] = 0x1
+	copy(buf[32:], key)
+	sha256.Block(h.stat, buf)
+	copy(buf, m1)
+	copy(buf[32:], m2)
+	sha256.Block(h.stat, buf)
+	copy(buf, zero64)
+	buf[0] = 0x80
+	buf[62] = 0x04
+	// buf[63] = 0x00
+	sha256.Block(h.stat, buf)
+	sha256.Int2Bytes(h.stat, out)
+}
+
+//prfFinish computes PRF of p, whose message is set in h.buf[:32].
+func (h *hasher) prfFinish(p *prf, out []byte) {
+	buf := h.buf
+	copy(buf[32:], zero64)
+	buf[32] = 0x80
+	buf[62] = 0x03
+	// buf[63] = 0x00
+	copy(h.stat, p.block1)
+	sha256.Block(h.stat, buf)
+	sha256.Int2Bytes(h.stat, out)
+}
+
+//m:32bytes
+func (h *hasher) prf(p *prf, m, out []byte) {
+	copy(h.buf, m[:32])
+	h.prfFinish(p, out)
+}
+
+func (h *hasher) prfInt(p *prf, m uint32, out []byte) {
+	copy(h.buf, zero64[:28])
+	binary.BigEndian.PutUint32(h.buf[28:], m)
+	h.prfFinish(p, out)
+}
+
+func (h *hasher) randHash(left, right []byte, p *prf, addrs addr, out []byte) {
+	addrs.set(adrKM, 0)
+	h.prf(p, addrs, h.key)
+	addrs.set(adrKM, 1)
+	h.prf(p, addrs, h.bm0)
+	addrs.set(adrKM, 2)
+	h.prf(p, addrs, h.bm1)
+	xorWords(h.bm0, left, h.bm0)
+	xorWords(h.bm1, right, h.bm1)
+	h.hashH(h.key, h.bm0, h.bm1, out)
+}
+
+func (h *hasher) chain(x []byte, start, step byte, p *prf, addrs addr, out []byte) {
+	copy(out, x)
+	for i := byte(0); i < step; i++ {
+		addrs.set(adrHash, uint32(start+i))
+		addrs.set(adrKM, 0)
+		h.prf(p, addrs, h.key)
+		addrs.set(adrKM, 1)
+		h.prf(p, addrs, h.bm0)
+		xorWords(h.bm0, out, h.bm0)
+		h.hashF(h.key, h.bm0, out)
+	}
+}
+
+//newWotsPrivKey computes WOTS+ private key at addrs of x into priv.
+func (h *hasher) newWotsPrivKey(x *PrivKey, addrs addr, priv wotsPrivKey) {
+	h.prf(x.wotsPRF, addrs, h.key)
+	p := h.wprf
+	p.init(h.key, h.buf)
+	l := h.lanes
+	for g := 0; g < len(priv); g += lanes {
+		end := g + lanes
+		if end > len(priv) {
+			end = len(priv)
+		}
+		for i := g; i < end; i++ {
+			copy(l.as[i-g], zero64[:32])
+			binary.BigEndian.PutUint32(l.as[i-g][28:], uint32(i))
+		}
+		l.prf(p, l.as[:end-g], priv[g:end])
+	}
+	l.wipe()
+	p.destroy()
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//known answers of SelfTest.
+//Outputs larger than a node are compared by their SHA256 digests.
+const (
+	katHashF   = "dc7a48014fc1fac8b52af39bc7ea5cafafabf8bb81fb8f880fdf3b4a4566795c"
+	katHashH   = "44446ec9624f654b1492e0f56726f5d034902e05f7bf8231a7152ad52158d365"
+	katHashMsg = "888b11f07b8b866ec41bc2acc2886c2e8f98e9b4b9b6d9cd41d5bbc73c86d9ce"
+	katPRF     = "0ffd9934fd5ce69376a7bf31d450b6ec0d4e90dfd97031d1050ebbd4b9712dd5"
+	katWOTSPub = "63a0f686580e7f4b0bee5b9975d549de5896c51aed31f33e9b25b4f333db07d6"
+	katWOTSSig = "5ef52cbf5f858980fd404ae369ef238022f6ce51e3d03b8e2370e91800db568e"
+	katRoot    = "4014956dd6cc3e5bcedc8835966f0902a8cc728943f224344e1beb107c807595"
+	katSig     = "412b25ae3e61b81d21299a94a0a692060232add47277192189b5234d9e33f87c"
+)
+
+var (
+	autoSelfTest int32
+	selfTestOnce sync.Once
+	selfTestErr  error
+)
+
+//SetAutoSelfTest sets whether SelfTest runs automatically before the first key operation
+//(key generation by functions which return errors, signing and verification).
+//If it fails, these operations fail, and Verify returns false, from then on.
+//NewMerkle cannot return errors, but Merkles made by it cannot sign either.
+func SetAutoSelfTest(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&autoSelfTest, v)
+}
+
+//checkSelfTest runs SelfTest once if SetAutoSelfTest is on and returns its result.
+func checkSelfTest() error {
+	if atomic.LoadInt32(&autoSelfTest) == 0 {
+		return nil
+	}
+	selfTestOnce.Do(func() {
+		selfTestErr = SelfTest()
+	})
+	return selfTestErr
+}
+
+//katBytes returns n bytes which start with start and increase one by one.
+func katBytes(start byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = start + byte(i)
+	}
+	return b
+}
+
+//katDigest returns SHA256 of all bs in hex.
+func katDigest(bs ...[]byte) string {
+	h := sha256.New()
+	for _, b := range bs {
+		if _, err := h.Write(b); err != nil {
+			panic(err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//selfTestError returns the error of the failed test name.
+func selfTestError(name string) error {
+	return fmt.Errorf("self-test of %s failed", name)
+}
+
+//SelfTest runs known-answer tests of the hash functions (F, H, H_msg and PRF),
+//WOTS+ and a tree with height 4, to catch broken hash implementations
+//(e.g. SIMD code on a new CPU) before using keys.
+//It returns an error which names the first failed test.
+func SelfTest() error {
+	check := func(name, expected, actual string) error {
+		if expected != actual {
+			return selfTestError(name)
+		}
+		return nil
+	}
+	key := katBytes(0x00, 32)
+	m1 := katBytes(0x20, 32)
+	m2 := katBytes(0x40, 32)
+	out := make([]byte, 32)
+
+	hashF(key, m1, out)
+	if err := check("F", katHashF, hex.EncodeToString(out)); err != nil {
+		return err
+	}
+	hashH(key, m1, m2, out)
+	if err := check("H", katHashH, hex.EncodeToString(out)); err != nil {
+		return err
+	}
+	if err := check("H_msg", katHashMsg, hex.EncodeToString(hashMsg(katBytes(0x60, 96), m1))); err != nil {
+		return err
+	}
+	newPRF(key).sum(m1, out)
+	if err := check("PRF", katPRF, hex.EncodeToString(out)); err != nil {
+		return err
+	}
+
+	//WOTS+
+	skPRF := newPRF(m2)
+	pubPRF := newPRF(key)
+	sk := make(wotsPrivKey, wlen)
+	pk := make(wotsPubKey, wlen)
+	for i := range sk {
+		sk[i] = make([]byte, n)
+		pk[i] = make([]byte, n)
+		skPRF.sumInt(uint32(i), sk[i])
+	}
+	addrs := make(addr, 32)
+	addrs.set(adrOTS, 5)
+	sk.newWotsPubKey(pubPRF, addrs, pk)
+	if err := check("WOTS+ key generation", katWOTSPub, katDigest(pk...)); err != nil {
+		return err
+	}
+	sig := sk.sign(m1, pubPRF, addrs, nil)
+	if err := check("WOTS+ signing", katWOTSSig, katDigest(sig...)); err != nil {
+		return err
+	}
+	if err := check("WOTS+ verification", katDigest(pk...), katDigest(sig.pubkey(m1, pubPRF, addrs, nil)...)); err != nil {
+		return err
+	}
+
+	//tree with height 4
+	mer := NewMerkle(4, key)
+	defer mer.Destroy()
+	if err := check("tree generation", katRoot, hex.EncodeToString(mer.priv.root)); err != nil {
+		return err
+	}
+	msg := []byte("XMSS self-test")
+	sig0 := mer.signBytes(mer.Leaf, msg, mer.auth)
+	mer.Traverse()
+	sig1 := mer.signBytes(mer.Leaf, msg, mer.auth)
+	if err := check("tree signing", katSig, katDigest(sig0, sig1)); err != nil {
+		return err
+	}
+	bad := append([]byte{}, sig1...)
+	bad[len(bad)-1] ^= 1
+	if !verify(nil, sig0, msg, mer.PublicKey()) || !verify(nil, sig1, msg, mer.PublicKey()) ||
+		verify(nil, bad, msg, mer.PublicKey()) {
+		return selfTestError("tree verification")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelfTestBroken(t *testing.T) {
+	old := blockLanes
+	defer func() {
+		blockLanes = old
+	}()
+	blockLanes = func(stats [][]uint32, blocks [][]byte) {
+		old(stats, blocks)
+		stats[0][0]++
+	}
+	if err := SelfTest(); err == nil {
+		t.Fatal("should detect a broken hash implementation")
+	} else {
+		t.Log(err)
+	}
+}
+
+func TestAutoSelfTest(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for self-tests.")
+	mer := NewMerkle(4, seed)
+	old := blockLanes
+	defer func() {
+		blockLanes = old
+		SetAutoSelfTest(false)
+		selfTestOnce = sync.Once{}
+		selfTestErr = nil
+	}()
+	blockLanes = func(stats [][]uint32, blocks [][]byte) {
+		old(stats, blocks)
+		stats[0][0]++
+	}
+	SetAutoSelfTest(true)
+	if _, err := mer.Sign(msg); err == nil {
+		t.Error("should not sign if the self-test fails")
+	}
+	if _, err := NewPrivKeyMT(seed, 20, 4); err == nil {
+		t.Error("should not generate keys if the self-test fails")
+	}
+	blockLanes = old
+	if _, err := mer.Sign(msg); err == nil {
+		t.Error("the result of the self-test must be kept")
+	}
+
+	selfTestOnce = sync.Once{}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(sig, msg, mer.PublicKey()) {
+		t.Error("signature is invalid")
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+)
+
+//rangeTag returns MAC of the leaf range [start, end) keyed by the secret seed,
+//so that the range cannot be edited without knowing the seed.
+func rangeTag(seed, pub []byte, layer uint32, tree, start, end uint64) []byte {
+	mac := hmac.New(sha256.New, seed)
+	b := make([]byte, 4+8+8+8)
+	binary.BigEndian.PutUint32(b, layer)
+	binary.BigEndian.PutUint64(b[4:], tree)
+	binary.BigEndian.PutUint64(b[12:], start)
+	binary.BigEndian.PutUint64(b[20:], end)
+	if _, err := mac.Write([]byte("xmss leaf range")); err != nil {
+		panic(err)
+	}
+	if _, err := mac.Write(pub); err != nil {
+		panic(err)
+	}
+	if _, err := mac.Write(b); err != nil {
+		panic(err)
+	}
+	return mac.Sum(nil)
+}
+
+func (m *Merkle) rangeTag() []byte {
+	return rangeTag(m.priv.wotsPRF.seed, m.PublicKey(), m.layer, m.tree, m.start, m.end)
+}
+
+//rangeCheck is the range and its tag which were verified last.
+type rangeCheck struct {
+	start uint64
+	end   uint64
+	tag   []byte
+}
+
+//checkRange verifies the tag of the range of m.
+//MAC is computed only if the range or the tag changed after it was verified last.
+func (m *Merkle) checkRange() error {
+	if m.start > m.end || m.end > 1<<m.Height {
+		return ValidationError("invalid leaf range")
+	}
+	c := &m.checked
+	if c.tag != nil && c.start == m.start && c.end == m.end && hmac.Equal(m.tag, c.tag) {
+		return nil
+	}
+	if !hmac.Equal(m.tag, m.rangeTag()) {
+		return ValidationError("leaf range was tampered")
+	}
+	c.start, c.end = m.start, m.end
+	c.tag = append(c.tag[:0], m.tag...)
+	return nil
+}
+
+//Range returns the range of leaves [start, end) which m can use to sign.
+func (m *Merkle) Range() (uint64, uint64) {
+	return m.start, m.end
+}
+
+func (nn *NH) clone() *NH {
+	node := &NH{
+		node:   make([]byte, len(nn.node)),
+		height: nn.height,
+		index:  nn.index,
+	}
+	copy(node.node, nn.node)
+	return node
+}
+
+func (s *Stack) clone() *Stack {
+	st := &Stack{
+		stack:  make([]*NH, len(s.stack), cap(s.stack)),
+		height: s.height,
+		leaf:   s.leaf,
+		layer:  s.layer,
+		tree:   s.tree,
+	}
+	for i, nn := range s.stack {
+		st.stack[i] = nn.clone()
+	}
+	return st
+}
+
+func (x *PrivKey) clone() *PrivKey {
+	root := make([]byte, len(x.root))
+	copy(root, x.root)
+	return &PrivKey{
+		msgPRF:  x.msgPRF.clone(),
+		wotsPRF: x.wotsPRF.clone(),
+		pubPRF:  x.pubPRF.clone(),
+		root:    root,
+		exec:    x.exec,
+	}
+}
+
+func (p *prf) clone() *prf {
+	seed := make([]byte, len(p.seed))
+	copy(seed, p.seed)
+	block1 := make([]uint32, len(p.block1))
+	copy(block1, p.block1)
+	return &prf{
+		seed:   seed,
+		block1: block1,
+	}
+}
+
+//clone returns a deep copy of m.
+func (m *Merkle) clone() *Merkle {
+	mm := &Merkle{
+		Leaf:    m.Leaf,
+		Height:  m.Height,
+		stacks:  make([]*Stack, len(m.stacks)),
+		auth:    make([][]byte, len(m.auth)),
+		priv:    m.priv.clone(),
+		layer:   m.layer,
+		tree:    m.tree,
+		start:   m.start,
+		end:     m.end,
+		tag:     make([]byte, len(m.tag)),
+		k:       m.k,
+		retain:  make([][]byte, len(m.retain)),
+		used:    make([]byte, len(m.used)),
+		async:   m.async,
+		epoch:   m.epoch,
+		guard:   m.guard,
+		suspect: m.suspect,
+	}
+	copy(mm.used, m.used)
+	//nodes in retain are never modified, so they can be shared.
+	copy(mm.retain, m.retain)
+	for i, s := range m.stacks {
+		mm.stacks[i] = s.clone()
+	}
+	for i, a := range m.auth {
+		mm.auth[i] = make([]byte, len(a))
+		copy(mm.auth[i], a)
+	}
+	copy(mm.tag, m.tag)
+	return mm
+}
+
+//Split carves the next num unused leaves off m and returns a new Merkle
+//which owns only the leaf range [LeafNo(), LeafNo()+num) with its own traversal state.
+//m is moved past the range, so m and the returned Merkle never use the same leaf.
+func (m *Merkle) Split(num uint64) (*Merkle, error) {
+	m.Flush()
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
+	if err := m.checkRange(); err != nil {
+		return nil, err
+	}
+	start := uint64(m.Leaf)
+	if start < m.start || start > m.end || num == 0 || num > m.end-start {
+		return nil, errors.New("invalid number of leaves to split")
+	}
+	child := m.clone()
+	child.start = start
+	child.end = start + num
+	child.tag = child.rangeTag()
+
+	if err := m.SetLeafNo(start + num); err != nil {
+		return nil, err
+	}
+	m.start = start + num
+	m.tag = m.rangeTag()
+	return child, nil
+}
+
+func (p *PrivKeyMT) rangeTag() []byte {
+	priv := p.merkle[p.d-1].priv
+	return rangeTag(priv.wotsPRF.seed, p.PublicKey(), p.d, 0, p.start, p.end)
+}
+
+func (p *PrivKeyMT) checkRange() error {
+	if p.start > p.end || p.end > numIndices(p.h) {
+		return ValidationError("invalid leaf range")
+	}
+	c := &p.checked
+	if c.tag != nil && c.start == p.start && c.end == p.end && hmac.Equal(p.tag, c.tag) {
+		return nil
+	}
+	if !hmac.Equal(p.tag, p.rangeTag()) {
+		return ValidationError("leaf range was tampered")
+	}
+	c.start, c.end = p.start, p.end
+	c.tag = append(c.tag[:0], p.tag...)
+	return nil
+}
+
+//Range returns the range of indices [start, end) which p can use to sign.
+func (p *PrivKeyMT) Range() (uint64, uint64) {
+	return p.start, p.end
+}
+
+//clone returns a deep copy of p.
+func (p *PrivKeyMT) clone() *PrivKeyMT {
+	pp := &PrivKeyMT{
+		index:   p.index,
+		merkle:  make([]*Merkle, len(p.merkle)),
+		h:       p.h,
+		d:       p.d,
+		start:   p.start,
+		end:     p.end,
+		tag:     make([]byte, len(p.tag)),
+		epoch:   p.epoch,
+		guard:   p.guard,
+		suspect: p.suspect,
+	}
+	for i, m := range p.merkle {
+		if m != nil {
+			pp.merkle[i] = m.clone()
+		}
+	}
+	copy(pp.tag, p.tag)
+	return pp
+}
+
+//Split carves the next num unused indices off p and returns a new PrivKeyMT
+//which owns only the index range [LeafNo(), LeafNo()+num) with its own trees.
+//p is moved past the range, so p and the returned key never use the same index.
+func (p *PrivKeyMT) Split(num uint64) (*PrivKeyMT, error) {
+	if p.destroyed() {
+		return nil, ErrDestroyed
+	}
+	if err := p.checkRange(); err != nil {
+		return nil, err
+	}
+	start := p.index
+	if start < p.start || start > p.end || num == 0 || num > p.end-start {
+		return nil, errors.New("invalid number of indices to split")
+	}
+	child := p.clone()
+	child.start = start
+	child.end = start + num
+	child.tag = child.rangeTag()
+
+	if err := p.SetLeafNo(start + num); err != nil {
+		return nil, err
+	}
+	p.start = start + num
+	p.tag = p.rangeTag()
+	return child, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	seed := generateSeed()
+	mer := NewMerkle(4, seed)
+	msg := []byte("This is a test for splitting XMSS.")
+	if _, err := mer.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	child, err := mer.Split(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start, end := child.Range(); start != 1 || end != 6 {
+		t.Error("invalid range of child", start, end)
+	}
+	if start, end := mer.Range(); start != 6 || end != 16 {
+		t.Error("invalid range of parent", start, end)
+	}
+	if mer.LeafNo() != 6 {
+		t.Error("invalid leaf no of parent", mer.LeafNo())
+	}
+	used := make(map[uint32]bool)
+	for _, m := range []*Merkle{child, mer} {
+		for {
+			sig, err := m.Sign(msg)
+			if err != nil {
+				break
+			}
+			if !Verify(sig, msg, m.PublicKey()) {
+				t.Error("XMSS sig is incorrect")
+			}
+			idx, err := IndexFromSig(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if used[idx] {
+				t.Error("leaf is used twice", idx)
+			}
+			used[idx] = true
+		}
+	}
+	if len(used) != 15 {
+		t.Error("invalid number of signatures", len(used))
+	}
+	if _, err := mer.Split(1); err == nil {
+		t.Error("should not split exhausted key")
+	}
+}
+
+func TestSplitTamper(t *testing.T) {
+	seed := generateSeed()
+	mer := NewMerkle(4, seed)
+	child, err := mer.Split(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child.end = 16
+	if _, err := child.Sign([]byte("msg")); err == nil {
+		t.Error("should not sign with tampered range")
+	}
+	child.end = 2
+	child.Leaf = 2
+	if _, err := child.Sign([]byte("msg")); err == nil {
+		t.Error("should not sign out of range")
+	}
+	child.Leaf = 0
+
+	dat, err := json.Marshal(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s merkle
+	if err := json.Unmarshal(dat, &s); err != nil {
+		t.Fatal(err)
+	}
+	s.End = 10
+	dat, err = json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child2 Merkle
+	if err := json.Unmarshal(dat, &child2); err == nil {
+		t.Error("should not load tampered range")
+	}
+
+	s.End = 0
+	s.Tag = nil
+	dat, err = json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child3 Merkle
+	if _, ok := json.Unmarshal(dat, &child3).(ValidationError); !ok {
+		t.Error("should not load a range without tag")
+	}
+}
+
+func TestSplitMT(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for splitting XMSS^MT.")
+	child, err := mer.Split(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start, end := child.Range(); start != 0 || end != 3 {
+		t.Error("invalid range of child", start, end)
+	}
+	if mer.LeafNo() != 3 {
+		t.Error("invalid leaf no of parent", mer.LeafNo())
+	}
+	for i := 0; i < 3; i++ {
+		sig, err := child.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMT(sig, msg, child.PublicKey()) {
+			t.Error("XMSS^MT sig is incorrect")
+		}
+	}
+	if _, err := child.Sign(msg); err == nil {
+		t.Error("should not sign out of range")
+	}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx := binary.BigEndian.Uint64(sig); idx != 3 {
+		t.Error("invalid index", idx)
+	}
+	if !VerifyMT(sig, msg, mer.PublicKey()) {
+		t.Error("XMSS^MT sig is incorrect")
+	}
+
+	dat, err := json.Marshal(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s privKeyMT
+	if err := json.Unmarshal(dat, &s); err != nil {
+		t.Fatal(err)
+	}
+	s.End = 0
+	s.Tag = nil
+	dat, err = json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child2 PrivKeyMT
+	if _, ok := json.Unmarshal(dat, &child2).(ValidationError); !ok {
+		t.Error("should not load a range without tag")
+	}
+
+	child.tag[0] ^= 1
+	if _, err := child.Split(1); err == nil {
+		t.Error("should not split tampered key")
+	}
+}
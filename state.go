@@ -0,0 +1,488 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+
+	sha256 "github.com/AidosKuneen/sha256-simd"
+	"github.com/vmihailenco/msgpack"
+)
+
+//A key can be stored in two parts, linked by the key ID:
+//State (or StateMT) has the traversal state, which has no secrets and changes after each signing,
+//and Seeds has the secret seeds, which never change.
+//So the state can be stored in an ordinary database while seeds are kept in a vault.
+
+//keyID returns the ID of the key whose public key is pub.
+func keyID(pub []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("xmss key id"))
+	h.Write(pub)
+	return h.Sum(nil)[:16]
+}
+
+//KeyID returns the ID of the key, which is derived from the public key.
+func (m *Merkle) KeyID() []byte {
+	return keyID(m.PublicKey())
+}
+
+//KeyID returns the ID of the key, which is derived from the public key.
+func (p *PrivKeyMT) KeyID() []byte {
+	return keyID(p.PublicKey())
+}
+
+//Seeds is the secret seeds of a key.
+type Seeds struct {
+	keyID    []byte
+	msgSeed  []byte
+	wotsSeed []byte
+	pubSeed  []byte
+}
+
+type seeds struct {
+	KeyID    []byte
+	MsgSeed  []byte
+	WotsSeed []byte
+	PubSeed  []byte
+}
+
+func newSeeds(id []byte, priv *PrivKey) *Seeds {
+	s := &Seeds{
+		keyID:    id,
+		msgSeed:  make([]byte, n),
+		wotsSeed: make([]byte, n),
+		pubSeed:  make([]byte, n),
+	}
+	copy(s.msgSeed, priv.msgPRF.seed)
+	copy(s.wotsSeed, priv.wotsPRF.seed)
+	copy(s.pubSeed, priv.pubPRF.seed)
+	return s
+}
+
+//KeyID returns the ID of the key of the seeds.
+func (s *Seeds) KeyID() []byte {
+	return s.keyID
+}
+
+//Destroy wipes the secret seeds.
+func (s *Seeds) Destroy() {
+	wipe(s.msgSeed)
+	wipe(s.wotsSeed)
+}
+
+func (s *Seeds) exports() *seeds {
+	return &seeds{
+		KeyID:    s.keyID,
+		MsgSeed:  s.msgSeed,
+		WotsSeed: s.wotsSeed,
+		PubSeed:  s.pubSeed,
+	}
+}
+
+func (s *Seeds) imports(sr *seeds) error {
+	if len(sr.MsgSeed) != n || len(sr.WotsSeed) != n || len(sr.PubSeed) != n {
+		return ValidationError("invalid length of seeds")
+	}
+	s.keyID = sr.KeyID
+	s.msgSeed = sr.MsgSeed
+	s.wotsSeed = sr.WotsSeed
+	s.pubSeed = sr.PubSeed
+	return nil
+}
+
+//MarshalJSON  marshals Seeds into valid JSON.
+func (s *Seeds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.exports())
+}
+
+//UnmarshalJSON  unmarshals JSON to Seeds.
+func (s *Seeds) UnmarshalJSON(b []byte) error {
+	var sr seeds
+	if err := json.Unmarshal(b, &sr); err != nil {
+		return err
+	}
+	return s.imports(&sr)
+}
+
+//EncodeMsgpack  marshals Seeds into valid msgpack.
+func (s *Seeds) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(s.exports())
+}
+
+//DecodeMsgpack  unmarshals msgpack to Seeds.
+func (s *Seeds) DecodeMsgpack(dec *msgpack.Decoder) error {
+	var sr seeds
+	if err := dec.Decode(&sr); err != nil {
+		return err
+	}
+	return s.imports(&sr)
+}
+
+//state is the traversal state of a Merkle without secrets.
+type state struct {
+	Root    []byte
+	PubSeed []byte
+	//Merkle is the traversal state whose Priv is nil.
+	Merkle *merkle
+}
+
+//state returns a copy of the traversal state of m.
+func (m *Merkle) state() *state {
+	mm := m.clone()
+	s := &state{
+		Root:    mm.priv.root,
+		PubSeed: mm.priv.pubPRF.seed,
+		Merkle:  mm.exports(),
+	}
+	s.Merkle.Priv = nil
+	mm.priv.msgPRF.destroy()
+	mm.priv.wotsPRF.destroy()
+	return s
+}
+
+//join returns Merkle made from a copy of s and sd.
+func (s *state) join(sd *Seeds) (*Merkle, error) {
+	if s.Merkle == nil || len(s.Root) != n || !bytes.Equal(s.PubSeed, sd.pubSeed) {
+		return nil, ValidationError("invalid state")
+	}
+	ms := *s.Merkle
+	ms.Auth = make([][]byte, len(s.Merkle.Auth))
+	for i, a := range s.Merkle.Auth {
+		ms.Auth[i] = make([]byte, len(a))
+		copy(ms.Auth[i], a)
+	}
+	ms.Stacks = make([]*Stack, len(s.Merkle.Stacks))
+	for i, st := range s.Merkle.Stacks {
+		if st == nil {
+			return nil, ValidationError("invalid state")
+		}
+		ms.Stacks[i] = st.clone()
+	}
+	//nodes in Retain are never modified, so they can be shared.
+	ms.Used = make([]byte, len(s.Merkle.Used))
+	copy(ms.Used, s.Merkle.Used)
+	root := make([]byte, n)
+	copy(root, s.Root)
+	ms.Priv = &PrivKey{
+		msgPRF:  newPRF(sd.msgSeed),
+		wotsPRF: newPRF(sd.wotsSeed),
+		pubPRF:  newPRF(sd.pubSeed),
+		root:    root,
+	}
+	m := &Merkle{}
+	if err := m.imports(&ms); err != nil {
+		ms.Priv.Destroy()
+		return nil, err
+	}
+	return m, nil
+}
+
+//State is the traversal state of Merkle without secret seeds.
+type State struct {
+	keyID []byte
+	s     *state
+}
+
+type xstate struct {
+	KeyID []byte
+	State *state
+}
+
+//State returns a copy of the traversal state of m, which has no secrets.
+//It must be saved after every signing as the whole Merkle is.
+func (m *Merkle) State() (*State, error) {
+	m.Flush()
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
+	return &State{
+		keyID: m.KeyID(),
+		s:     m.state(),
+	}, nil
+}
+
+//Seeds returns a copy of the secret seeds of m.
+func (m *Merkle) Seeds() (*Seeds, error) {
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
+	return newSeeds(m.KeyID(), m.priv), nil
+}
+
+//NewMerkleFromState makes Merkle from the traversal state st and its seeds sd.
+//st can be used again after this, though it must not be used to sign twice.
+func NewMerkleFromState(st *State, sd *Seeds) (*Merkle, error) {
+	if st.s == nil {
+		return nil, ValidationError("invalid state")
+	}
+	if !hmac.Equal(st.keyID, sd.keyID) {
+		return nil, errors.New("seeds are not for the state")
+	}
+	m, err := st.s.join(sd)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(m.KeyID(), st.keyID) {
+		m.Destroy()
+		return nil, errors.New("invalid key ID of the state")
+	}
+	return m, nil
+}
+
+//KeyID returns the ID of the key of the state.
+func (st *State) KeyID() []byte {
+	return st.keyID
+}
+
+func (st *State) exports() *xstate {
+	return &xstate{
+		KeyID: st.keyID,
+		State: st.s,
+	}
+}
+
+func (st *State) imports(s *xstate) error {
+	if s.State == nil {
+		return ValidationError("invalid state")
+	}
+	st.keyID = s.KeyID
+	st.s = s.State
+	return nil
+}
+
+//MarshalJSON  marshals State into valid JSON.
+func (st *State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(st.exports())
+}
+
+//UnmarshalJSON  unmarshals JSON to State.
+func (st *State) UnmarshalJSON(b []byte) error {
+	var s xstate
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return st.imports(&s)
+}
+
+//EncodeMsgpack  marshals State into valid msgpack.
+func (st *State) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(st.exports())
+}
+
+//DecodeMsgpack  unmarshals msgpack to State.
+func (st *State) DecodeMsgpack(dec *msgpack.Decoder) error {
+	var s xstate
+	if err := dec.Decode(&s); err != nil {
+		return err
+	}
+	return st.imports(&s)
+}
+
+//StateMT is the traversal state of PrivKeyMT without secret seeds.
+type StateMT struct {
+	keyID  []byte
+	index  uint64
+	h      uint32
+	d      uint32
+	start  uint64
+	end    uint64
+	tag    []byte
+	merkle []*state
+	epoch  uint64
+	//suspect is nil in states made before the flag was stored.
+	suspect *bool
+	mac     []byte
+}
+
+type stateMT struct {
+	KeyID   []byte
+	Index   uint64
+	H       uint32
+	D       uint32
+	Start   uint64
+	End     uint64
+	Tag     []byte
+	Merkle  []*state
+	Epoch   uint64
+	Suspect *bool
+	MAC     []byte
+}
+
+//State returns a copy of the traversal state of p, which has no secrets.
+//It must be saved after every signing as the whole PrivKeyMT is.
+func (p *PrivKeyMT) State() (*StateMT, error) {
+	if p.destroyed() {
+		return nil, ErrDestroyed
+	}
+	suspect := p.suspect
+	st := &StateMT{
+		keyID:   p.KeyID(),
+		index:   p.index,
+		h:       p.h,
+		d:       p.d,
+		start:   p.start,
+		end:     p.end,
+		tag:     make([]byte, len(p.tag)),
+		merkle:  make([]*state, len(p.merkle)),
+		epoch:   p.epoch,
+		suspect: &suspect,
+		mac:     p.mac(binaryVersion),
+	}
+	copy(st.tag, p.tag)
+	for i, m := range p.merkle {
+		if m != nil {
+			st.merkle[i] = m.state()
+		}
+	}
+	return st, nil
+}
+
+//Seeds returns a copy of the secret seeds of p.
+func (p *PrivKeyMT) Seeds() (*Seeds, error) {
+	if p.destroyed() {
+		return nil, ErrDestroyed
+	}
+	return newSeeds(p.KeyID(), p.merkle[p.d-1].priv), nil
+}
+
+//NewPrivKeyMTFromState makes PrivKeyMT from the traversal state st and its seeds sd.
+//st can be used again after this, though it must not be used to sign twice.
+func NewPrivKeyMTFromState(st *StateMT, sd *Seeds) (*PrivKeyMT, error) {
+	if !hmac.Equal(st.keyID, sd.keyID) {
+		return nil, errors.New("seeds are not for the state")
+	}
+	if _, err := PublickeyMTHeader(st.h, st.d); err != nil {
+		return nil, err
+	}
+	if uint32(len(st.merkle)) != st.d || st.merkle[st.d-1] == nil {
+		return nil, ValidationError("invalid state")
+	}
+	s := &privKeyMT{
+		Index:   st.index,
+		H:       st.h,
+		D:       st.d,
+		Start:   st.start,
+		End:     st.end,
+		Tag:     make([]byte, len(st.tag)),
+		Merkle:  make([]*Merkle, st.d),
+		Epoch:   st.epoch,
+		Suspect: st.suspect,
+		MAC:     st.mac,
+	}
+	copy(s.Tag, st.tag)
+	destroy := func() {
+		for _, m := range s.Merkle {
+			if m != nil {
+				m.Destroy()
+			}
+		}
+	}
+	for i, ms := range st.merkle {
+		if ms == nil {
+			continue
+		}
+		m, err := ms.join(sd)
+		if err != nil {
+			destroy()
+			return nil, err
+		}
+		s.Merkle[i] = m
+	}
+	var p PrivKeyMT
+	if err := p.imports(s); err != nil {
+		destroy()
+		return nil, err
+	}
+	if !hmac.Equal(p.KeyID(), st.keyID) {
+		destroy()
+		return nil, errors.New("invalid key ID of the state")
+	}
+	return &p, nil
+}
+
+//KeyID returns the ID of the key of the state.
+func (st *StateMT) KeyID() []byte {
+	return st.keyID
+}
+
+func (st *StateMT) exports() *stateMT {
+	return &stateMT{
+		KeyID:   st.keyID,
+		Index:   st.index,
+		H:       st.h,
+		D:       st.d,
+		Start:   st.start,
+		End:     st.end,
+		Tag:     st.tag,
+		Merkle:  st.merkle,
+		Epoch:   st.epoch,
+		Suspect: st.suspect,
+		MAC:     st.mac,
+	}
+}
+
+func (st *StateMT) imports(s *stateMT) {
+	st.keyID = s.KeyID
+	st.index = s.Index
+	st.h = s.H
+	st.d = s.D
+	st.start = s.Start
+	st.end = s.End
+	st.tag = s.Tag
+	st.merkle = s.Merkle
+	st.epoch = s.Epoch
+	st.suspect = s.Suspect
+	st.mac = s.MAC
+}
+
+//MarshalJSON  marshals StateMT into valid JSON.
+func (st *StateMT) MarshalJSON() ([]byte, error) {
+	return json.Marshal(st.exports())
+}
+
+//UnmarshalJSON  unmarshals JSON to StateMT.
+func (st *StateMT) UnmarshalJSON(b []byte) error {
+	var s stateMT
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	st.imports(&s)
+	return nil
+}
+
+//EncodeMsgpack  marshals StateMT into valid msgpack.
+func (st *StateMT) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(st.exports())
+}
+
+//DecodeMsgpack  unmarshals msgpack to StateMT.
+func (st *StateMT) DecodeMsgpack(dec *msgpack.Decoder) error {
+	var s stateMT
+	if err := dec.Decode(&s); err != nil {
+		return err
+	}
+	st.imports(&s)
+	return nil
+}
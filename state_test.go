@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+func TestState(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for separated states.")
+	mer := NewMerkle(5, seed)
+	if _, err := mer.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	st, err := mer.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := mer.Seeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(st.KeyID(), sd.KeyID()) || !bytes.Equal(st.KeyID(), mer.KeyID()) {
+		t.Error("key IDs differ")
+	}
+	dat, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdat, err := msgpack.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wotsSeed, msgSeed, _ := deriveSeeds(seed)
+	for _, s := range [][]byte{wotsSeed, msgSeed} {
+		if bytes.Contains(dat, s) || bytes.Contains(mdat, s) {
+			t.Error("state must not contain secrets")
+		}
+	}
+	sdat, err := json.Marshal(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var st2 State
+	if err := json.Unmarshal(dat, &st2); err != nil {
+		t.Fatal(err)
+	}
+	var st3 State
+	if err := msgpack.Unmarshal(mdat, &st3); err != nil {
+		t.Fatal(err)
+	}
+	var sd2 Seeds
+	if err := json.Unmarshal(sdat, &sd2); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*State{&st2, &st3} {
+		mer2, err := NewMerkleFromState(s, &sd2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := mer2.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sig2) {
+			t.Error("signatures differ")
+		}
+	}
+
+	other, err := NewMerkle(5, generateSeed()).Seeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMerkleFromState(&st2, other); err == nil {
+		t.Error("should not join seeds of another key")
+	}
+	other.keyID = st2.KeyID()
+	if _, err := NewMerkleFromState(&st2, other); err == nil {
+		t.Error("should not join seeds of another key")
+	}
+	if _, err := NewMerkleFromState(&State{}, &Seeds{}); err != ValidationError("invalid state") {
+		t.Error("should not join an empty state", err)
+	}
+	if _, err := NewPrivKeyMTFromState(&StateMT{}, &Seeds{}); err == nil {
+		t.Error("should not join an empty state")
+	}
+}
+
+func TestStateMT(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for separated states of XMSS^MT.")
+	mt, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mt.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	st, err := mt.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := mt.Seeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdat, err := msgpack.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st2 StateMT
+	if err := msgpack.Unmarshal(mdat, &st2); err != nil {
+		t.Fatal(err)
+	}
+	dat, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st3 StateMT
+	if err := json.Unmarshal(dat, &st3); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mt.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*StateMT{&st2, &st3} {
+		mt2, err := NewPrivKeyMTFromState(s, sd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := mt2.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sig2) {
+			t.Error("signatures differ")
+		}
+	}
+	st2.end++
+	if _, err := NewPrivKeyMTFromState(&st2, sd); err == nil {
+		t.Error("should not join a tampered state")
+	}
+}
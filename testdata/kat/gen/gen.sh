@@ -0,0 +1,51 @@
+#!/bin/sh
+# gen.sh regenerates the known-answer vectors in testdata/kat with katgen.c.
+# It needs a C compiler and OpenSSL (libcrypto). Sets with trees of height 20
+# take hours.
+#
+#	sh gen.sh
+#
+# builds katgen.c with rfc8391/, an implementation of RFC 8391 which shares no
+# code with this package, and writes the vectors.
+#
+#	REF=<revision> sh gen.sh
+#
+# also builds katgen.c with the XMSS reference implementation at REF (it needs
+# git and network access), and fails unless both write the same vectors.
+# REF must be a revision of xmss-reference that derives WOTS+ private keys as
+# PRF(PRF(SK_SEED, ADRS), toByte(i, 32)), i.e. before it switched to the
+# key generation of NIST SP 800-208, which is not compatible with this package.
+set -e
+
+cd "$(dirname "$0")"
+gen=$(pwd)
+dir=$(mktemp -d)
+trap 'rm -rf "$dir"' EXIT
+
+(cd rfc8391 && cc -O2 -Wno-deprecated-declarations -I. -o "$dir/katgen" "$gen/katgen.c" rfc8391.c -lcrypto)
+
+if [ -n "$REF" ]; then
+	git clone -q https://github.com/XMSS/xmss-reference "$dir/ref"
+	git -C "$dir/ref" checkout -q "$REF"
+	(cd "$dir/ref" && cc -O3 -I. -o "$dir/katgen-ref" "$gen/katgen.c" \
+		params.c hash.c fips202.c hash_address.c wots.c xmss_commons.c xmss_core.c utils.c -lcrypto)
+fi
+
+for s in XMSS-SHA2_10_256 XMSS-SHA2_16_256 XMSS-SHA2_20_256 \
+	XMSSMT-SHA2_20/2_256 XMSSMT-SHA2_20/4_256 \
+	XMSSMT-SHA2_40/2_256 XMSSMT-SHA2_40/4_256 XMSSMT-SHA2_40/8_256 \
+	XMSSMT-SHA2_60/3_256 XMSSMT-SHA2_60/6_256 XMSSMT-SHA2_60/12_256; do
+	echo "$s" >&2
+	out="../$(echo "$s" | tr / _).json"
+	"$dir/katgen" "$s" > "$out"
+	if [ -n "$REF" ]; then
+		"$dir/katgen-ref" "$s" > "$dir/ref.json"
+		if ! cmp -s "$out" "$dir/ref.json"; then
+			echo "xmss-reference at $REF writes other vectors for $s" >&2
+			exit 1
+		fi
+	fi
+done
+if [ -n "$REF" ]; then
+	echo "xmss-reference at $(git -C "$dir/ref" rev-parse HEAD) writes the same vectors" >&2
+fi
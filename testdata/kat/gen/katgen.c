@@ -0,0 +1,157 @@
+/*
+ * katgen writes the known-answer vectors of one parameter set in testdata/kat.
+ * It is built with rfc8391/ or with the XMSS reference implementation
+ * (https://github.com/XMSS/xmss-reference), whose API rfc8391/ provides.
+ *
+ * Seeds and messages are derived from the name of the set as below, and the key
+ * is made by xmssmt_core_seed_keypair (SK_SEED || SK_PRF || SEED).
+ * Each signature is checked by xmssmt_core_sign_open before it is written, so a
+ * revision that wipes the key before its last signature fails instead of writing
+ * a wrong vector.
+ *
+ *	SK_SEED = SHA256(name || "/SK_SEED")
+ *	SK_PRF  = SHA256(name || "/SK_PRF")
+ *	SEED    = SHA256(name || "/SEED")
+ *	message at index 0           = "This is a known-answer test for " || name || "."
+ *	message at index 1           = ""
+ *	message at the last index    = SHA256(name || "/message") repeated 4 times
+ *
+ * The last index is omitted for sets whose trees have height 20.
+ * See gen.sh for how to build and run it.
+ */
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include <openssl/sha.h>
+
+#include "params.h"
+#include "utils.h"
+#include "xmss_core.h"
+
+static void derive(unsigned char *out, const char *name, const char *label)
+{
+    size_t ln = strlen(name), ll = strlen(label);
+    unsigned char *buf = malloc(ln + 1 + ll);
+
+    memcpy(buf, name, ln);
+    buf[ln] = '/';
+    memcpy(buf + ln + 1, label, ll);
+    SHA256(buf, ln + 1 + ll, out);
+    free(buf);
+}
+
+static void hex(const unsigned char *b, unsigned long long len)
+{
+    unsigned long long i;
+
+    for (i = 0; i < len; i++) {
+        printf("%02x", b[i]);
+    }
+}
+
+int main(int argc, char **argv)
+{
+    xmss_params params;
+    uint32_t oid;
+    int mt, i, nidx = 3;
+    const char *name;
+    unsigned char seed[96], *pk, *sk, *sk2, *sm, *m;
+    unsigned char msgs[3][128];
+    unsigned long long mlens[3], idx[3], smlen, mlen;
+
+    if (argc != 2) {
+        fprintf(stderr, "usage: %s <parameter set, e.g. XMSSMT-SHA2_20/2_256>\n", argv[0]);
+        return 1;
+    }
+    name = argv[1];
+    mt = strncmp(name, "XMSSMT", 6) == 0;
+    if (mt) {
+        if (xmssmt_str_to_oid(&oid, name) || xmssmt_parse_oid(&params, oid)) {
+            fprintf(stderr, "unknown parameter set %s\n", name);
+            return 1;
+        }
+    } else {
+        if (xmss_str_to_oid(&oid, name) || xmss_parse_oid(&params, oid)) {
+            fprintf(stderr, "unknown parameter set %s\n", name);
+            return 1;
+        }
+    }
+    if (params.n != 32) {
+        fprintf(stderr, "n must be 32\n");
+        return 1;
+    }
+
+    derive(seed, name, "SK_SEED");
+    derive(seed + 32, name, "SK_PRF");
+    derive(seed + 64, name, "SEED");
+
+    mlens[0] = snprintf((char *)msgs[0], sizeof(msgs[0]), "This is a known-answer test for %s.", name);
+    mlens[1] = 0;
+    derive(msgs[2], name, "message");
+    for (i = 1; i < 4; i++) {
+        memcpy(msgs[2] + 32 * i, msgs[2], 32);
+    }
+    mlens[2] = 128;
+    idx[0] = 0;
+    idx[1] = 1;
+    idx[2] = (1ULL << params.full_height) - 1;
+    if (params.tree_height >= 20) {
+        nidx = 2;
+    }
+
+    pk = malloc(params.pk_bytes);
+    sk = malloc(params.sk_bytes);
+    sk2 = malloc(params.sk_bytes);
+    sm = malloc(params.sig_bytes + 128);
+    m = malloc(params.sig_bytes + 128);
+    if (xmssmt_core_seed_keypair(&params, pk, sk, seed)) {
+        fprintf(stderr, "failed to make a key\n");
+        return 1;
+    }
+
+    printf("{\n");
+    printf("\t\"Name\": \"%s\",\n", name);
+    printf("\t\"OID\": %u,\n", oid);
+    printf("\t\"MT\": %s,\n", mt ? "true" : "false");
+    printf("\t\"H\": %u,\n", params.full_height);
+    printf("\t\"D\": %u,\n", params.d);
+    printf("\t\"SKSeed\": \"");
+    hex(seed, 32);
+    printf("\",\n\t\"SKPRF\": \"");
+    hex(seed + 32, 32);
+    printf("\",\n\t\"PubSeed\": \"");
+    hex(seed + 64, 32);
+    printf("\",\n\t\"PublicKey\": \"%08x", oid);
+    hex(pk, params.pk_bytes);
+    printf("\",\n\t\"Vectors\": [\n");
+    for (i = 0; i < nidx; i++) {
+        /* sign with a copy, as the reference advances the index in the key. */
+        memcpy(sk2, sk, params.sk_bytes);
+        ull_to_bytes(sk2, params.index_bytes, idx[i]);
+        if (xmssmt_core_sign(&params, sk2, sm, &smlen, msgs[i], mlens[i])) {
+            fprintf(stderr, "failed to sign at %llu\n", idx[i]);
+            return 1;
+        }
+        if (xmssmt_core_sign_open(&params, m, &mlen, sm, smlen, pk)) {
+            fprintf(stderr, "invalid signature at %llu\n", idx[i]);
+            return 1;
+        }
+        printf("\t\t{\n");
+        printf("\t\t\t\"Index\": %llu,\n", idx[i]);
+        printf("\t\t\t\"Message\": \"");
+        hex(msgs[i], mlens[i]);
+        printf("\",\n\t\t\t\"Signature\": \"");
+        hex(sm, params.sig_bytes);
+        printf("\"\n\t\t}%s\n", i == nidx - 1 ? "" : ",");
+    }
+    printf("\t]\n}\n");
+
+    free(pk);
+    free(sk);
+    free(sk2);
+    free(sm);
+    free(m);
+    return 0;
+}
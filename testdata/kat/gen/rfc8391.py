@@ -0,0 +1,174 @@
+# rfc8391.py is a straightforward implementation of RFC 8391 (SHA2, n=32, w=16), written
+# separately from this package, with the WOTS+ key derivation of the XMSS reference
+# implementation. It recomputes the public keys and signatures of KAT vectors to cross-check them:
+#
+#	python3 rfc8391.py ../XMSS-SHA2_10_256.json ...
+#
+# It keeps whole trees in memory, so sets with trees of height 20 are too slow for it.
+import hashlib, json, sys
+
+N = 32
+W = 16
+LEN1 = 64
+LEN2 = 3
+LEN = 67
+
+def tb(x, n): return x.to_bytes(n, 'big')
+def sha(b): return hashlib.sha256(b).digest()
+def F(k, m): return sha(tb(0, 32) + k + m)
+def H(k, m): return sha(tb(1, 32) + k + m)
+def Hmsg(k, m): return sha(tb(2, 32) + k + m)
+def PRF(k, m): return sha(tb(3, 32) + k + m)
+def xor(a, b): return bytes(x ^ y for x, y in zip(a, b))
+
+class Addr:
+    def __init__(self, layer=0, tree=0):
+        self.w = [0] * 8
+        self.w[0] = layer
+        self.w[1] = tree >> 32
+        self.w[2] = tree & 0xffffffff
+    def copy(self):
+        a = Addr(); a.w = list(self.w); return a
+    def b(self): return b''.join(tb(x, 4) for x in self.w)
+    def set_type(self, t):
+        self.w[3] = t; self.w[4] = self.w[5] = self.w[6] = self.w[7] = 0
+
+def rand_f(x, seed, a):
+    a.w[7] = 0; k = PRF(seed, a.b())
+    a.w[7] = 1; bm = PRF(seed, a.b())
+    return F(k, xor(x, bm))
+
+def rand_h(l, r, seed, a):
+    a.w[7] = 0; k = PRF(seed, a.b())
+    a.w[7] = 1; bm0 = PRF(seed, a.b())
+    a.w[7] = 2; bm1 = PRF(seed, a.b())
+    return H(k, xor(l, bm0) + xor(r, bm1))
+
+def chain(x, start, steps, seed, a):
+    for i in range(start, start + steps):
+        a.w[6] = i
+        x = rand_f(x, seed, a)
+    return x
+
+def base_w(x, outlen):
+    out = []
+    for byte in x:
+        out.append(byte >> 4); out.append(byte & 15)
+    return out[:outlen]
+
+def msg_digits(m):
+    d = base_w(m, LEN1)
+    csum = sum(W - 1 - x for x in d)
+    csum <<= 4
+    d += base_w(tb(csum, 2), LEN2)
+    return d
+
+def wots_sk(sk_seed, a):
+    a = a.copy(); a.w[5] = a.w[6] = a.w[7] = 0
+    s = PRF(sk_seed, a.b())
+    return [PRF(s, tb(i, 32)) for i in range(LEN)]
+
+def wots_pk(sk, seed, a):
+    out = []
+    for i in range(LEN):
+        a.w[5] = i
+        out.append(chain(sk[i], 0, W - 1, seed, a))
+    return out
+
+def wots_sign(sk, m, seed, a):
+    d = msg_digits(m)
+    out = []
+    for i in range(LEN):
+        a.w[5] = i
+        out.append(chain(sk[i], 0, d[i], seed, a))
+    return out
+
+def ltree(pk, seed, a):
+    pk = list(pk)
+    l = LEN
+    a.w[5] = 0
+    while l > 1:
+        for i in range(l // 2):
+            a.w[6] = i
+            pk[i] = rand_h(pk[2 * i], pk[2 * i + 1], seed, a)
+        if l & 1:
+            pk[l // 2] = pk[l - 1]
+        l = (l + 1) // 2
+        a.w[5] += 1
+    return pk[0]
+
+def leaf(sk_seed, seed, layer, tree, i):
+    a = Addr(layer, tree); a.set_type(0); a.w[4] = i
+    sk = wots_sk(sk_seed, a)
+    pk = wots_pk(sk, seed, a)
+    b = Addr(layer, tree); b.set_type(1); b.w[4] = i
+    return ltree(pk, seed, b)
+
+def tree_levels(sk_seed, seed, h, layer, tree):
+    lv = [[leaf(sk_seed, seed, layer, tree, i) for i in range(1 << h)]]
+    a = Addr(layer, tree); a.set_type(2)
+    for z in range(h):
+        prev = lv[-1]; cur = []
+        for j in range(len(prev) // 2):
+            a.w[5] = z; a.w[6] = j
+            cur.append(rand_h(prev[2 * j], prev[2 * j + 1], seed, a))
+        lv.append(cur)
+    return lv
+
+def tree_sig(lv, sk_seed, seed, h, layer, tree, i, m):
+    a = Addr(layer, tree); a.set_type(0); a.w[4] = i
+    sk = wots_sk(sk_seed, a)
+    sig = wots_sign(sk, m, seed, a)
+    auth = [lv[z][(i >> z) ^ 1] for z in range(h)]
+    return b''.join(sig) + b''.join(auth)
+
+_cache = {}
+def levels(sk_seed, seed, h, layer, tree):
+    k = (sk_seed, seed, h, layer, tree)
+    if k not in _cache:
+        _cache[k] = tree_levels(sk_seed, seed, h, layer, tree)
+    return _cache[k]
+
+def xmss(sk_seed, sk_prf, seed, h, idx, msg):
+    lv = levels(sk_seed, seed, h, 0, 0)
+    root = lv[h][0]
+    r = PRF(sk_prf, tb(idx, 32))
+    m = Hmsg(r + root + tb(idx, 32), msg)
+    return root, tb(idx, 4) + r + tree_sig(lv, sk_seed, seed, h, 0, 0, idx, m)
+
+def xmssmt(sk_seed, sk_prf, seed, h, d, idx, msg):
+    hh = h // d
+    top = levels(sk_seed, seed, hh, d - 1, 0)
+    root = top[hh][0]
+    r = PRF(sk_prf, tb(idx, 32))
+    m = Hmsg(r + root + tb(idx, 32), msg)
+    out = tb(idx, (h + 7) // 8) + r
+    tree = idx >> hh
+    leafi = idx & ((1 << hh) - 1)
+    for j in range(d):
+        lv = levels(sk_seed, seed, hh, j, tree)
+        out += tree_sig(lv, sk_seed, seed, hh, j, tree, leafi, m)
+        m = lv[hh][0]
+        leafi = tree & ((1 << hh) - 1)
+        tree >>= hh
+    return root, out
+
+
+files = sys.argv[1:]
+ok = True
+for fn in files:
+    f = json.load(open(fn))
+    sk, prf, pub = (bytes.fromhex(f[k]) for k in ('SKSeed', 'SKPRF', 'PubSeed'))
+    for v in f['Vectors']:
+        msg = bytes.fromhex(v['Message'])
+        if f['MT']:
+            root, sig = xmssmt(sk, prf, pub, f['H'], f['D'], v['Index'], msg)
+        else:
+            root, sig = xmss(sk, prf, pub, f['H'], v['Index'], msg)
+        pk = f['OID'].to_bytes(4, 'big') + root + pub
+        r = (pk.hex() == f['PublicKey'], sig.hex() == v['Signature'])
+        ok &= all(r)
+        print(f['Name'], v['Index'], r, flush=True)
+    _cache.clear()
+print("ALL OK" if ok else "MISMATCH")
+sys.exit(0 if ok else 1)
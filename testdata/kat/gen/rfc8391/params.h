@@ -0,0 +1,28 @@
+/*
+ * The subset of params.h of the XMSS reference implementation used by katgen.c,
+ * for the SHA2 parameter sets with n=32 only.
+ */
+#ifndef RFC8391_PARAMS_H
+#define RFC8391_PARAMS_H
+
+#include <stdint.h>
+
+typedef struct {
+    unsigned int n;
+    unsigned int wots_len;
+    unsigned int wots_sig_bytes;
+    unsigned int full_height;
+    unsigned int tree_height;
+    unsigned int d;
+    unsigned int index_bytes;
+    unsigned int sig_bytes;
+    unsigned int pk_bytes;
+    unsigned long long sk_bytes;
+} xmss_params;
+
+int xmss_str_to_oid(uint32_t *oid, const char *s);
+int xmssmt_str_to_oid(uint32_t *oid, const char *s);
+int xmss_parse_oid(xmss_params *params, const uint32_t oid);
+int xmssmt_parse_oid(xmss_params *params, const uint32_t oid);
+
+#endif
@@ -0,0 +1,515 @@
+/*
+ * rfc8391.c is a straightforward implementation of XMSS and XMSS^MT in RFC 8391
+ * (SHA2, n=32, w=16) with the API of the XMSS reference implementation used by
+ * katgen.c. It is written from the RFC independently of the Go package, and
+ * derives WOTS+ private keys like the reference (before NIST SP 800-208):
+ *
+ *	seed = PRF(SK_SEED, ADRS with chain, hash and keyAndMask set to 0)
+ *	sk[i] = PRF(seed, toByte(i, 32))
+ *
+ * Whole subtrees are kept in memory, so that signing several indices of a set
+ * computes each subtree once.
+ */
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include <openssl/sha.h>
+
+#include "params.h"
+#include "utils.h"
+#include "xmss_core.h"
+
+#define N 32
+#define W 16
+#define LEN1 64
+#define LEN2 3
+#define LEN 67
+
+#define ADDR_OTS 0
+#define ADDR_LTREE 1
+#define ADDR_HASHTREE 2
+
+static const struct {
+    const char *name;
+    uint32_t oid;
+    unsigned int h, d;
+} sets[] = {
+    {"XMSS-SHA2_10_256", 0x01, 10, 1},
+    {"XMSS-SHA2_16_256", 0x02, 16, 1},
+    {"XMSS-SHA2_20_256", 0x03, 20, 1},
+    {"XMSSMT-SHA2_20/2_256", 0x01, 20, 2},
+    {"XMSSMT-SHA2_20/4_256", 0x02, 20, 4},
+    {"XMSSMT-SHA2_40/2_256", 0x03, 40, 2},
+    {"XMSSMT-SHA2_40/4_256", 0x04, 40, 4},
+    {"XMSSMT-SHA2_40/8_256", 0x05, 40, 8},
+    {"XMSSMT-SHA2_60/3_256", 0x06, 60, 3},
+    {"XMSSMT-SHA2_60/6_256", 0x07, 60, 6},
+    {"XMSSMT-SHA2_60/12_256", 0x08, 60, 12},
+};
+
+#define NSETS (sizeof(sets) / sizeof(sets[0]))
+#define NXMSS 3
+
+static int str_to_oid(uint32_t *oid, const char *s, size_t from, size_t to)
+{
+    size_t i;
+
+    for (i = from; i < to; i++) {
+        if (strcmp(sets[i].name, s) == 0) {
+            *oid = sets[i].oid;
+            return 0;
+        }
+    }
+    return -1;
+}
+
+int xmss_str_to_oid(uint32_t *oid, const char *s)
+{
+    return str_to_oid(oid, s, 0, NXMSS);
+}
+
+int xmssmt_str_to_oid(uint32_t *oid, const char *s)
+{
+    return str_to_oid(oid, s, NXMSS, NSETS);
+}
+
+static int parse_oid(xmss_params *params, uint32_t oid, size_t from, size_t to)
+{
+    size_t i;
+
+    for (i = from; i < to; i++) {
+        if (sets[i].oid != oid) {
+            continue;
+        }
+        params->n = N;
+        params->wots_len = LEN;
+        params->wots_sig_bytes = LEN * N;
+        params->full_height = sets[i].h;
+        params->d = sets[i].d;
+        params->tree_height = sets[i].h / sets[i].d;
+        params->index_bytes = sets[i].d == 1 ? 4 : (sets[i].h + 7) / 8;
+        params->sig_bytes = params->index_bytes + N +
+                            params->d * params->wots_sig_bytes + params->full_height * N;
+        params->pk_bytes = 2 * N;
+        params->sk_bytes = params->index_bytes + 4 * N;
+        return 0;
+    }
+    return -1;
+}
+
+int xmss_parse_oid(xmss_params *params, const uint32_t oid)
+{
+    return parse_oid(params, oid, 0, NXMSS);
+}
+
+int xmssmt_parse_oid(xmss_params *params, const uint32_t oid)
+{
+    return parse_oid(params, oid, NXMSS, NSETS);
+}
+
+void ull_to_bytes(unsigned char *out, unsigned int outlen, unsigned long long in)
+{
+    while (outlen > 0) {
+        out[--outlen] = in & 0xff;
+        in >>= 8;
+    }
+}
+
+unsigned long long bytes_to_ull(const unsigned char *in, unsigned int inlen)
+{
+    unsigned long long r = 0;
+    unsigned int i;
+
+    for (i = 0; i < inlen; i++) {
+        r = (r << 8) | in[i];
+    }
+    return r;
+}
+
+/* hashes of RFC 8391 section 5.1, with toByte(x, 32) as the prefix. */
+static void hash(unsigned char *out, unsigned int prefix, const unsigned char *key,
+                 const unsigned char *m, size_t mlen)
+{
+    unsigned char p[32] = {0};
+    SHA256_CTX c;
+
+    p[31] = prefix;
+    SHA256_Init(&c);
+    SHA256_Update(&c, p, 32);
+    SHA256_Update(&c, key, N);
+    SHA256_Update(&c, m, mlen);
+    SHA256_Final(out, &c);
+}
+
+static void prf(unsigned char *out, const unsigned char *key, const unsigned char *m)
+{
+    hash(out, 3, key, m, 32);
+}
+
+/* PRF(SEED, ADRS) with the state after toByte(3, 32) || SEED. */
+static SHA256_CTX seeded;
+
+static void set_seed(const unsigned char *seed)
+{
+    unsigned char p[32] = {0};
+
+    p[31] = 3;
+    SHA256_Init(&seeded);
+    SHA256_Update(&seeded, p, 32);
+    SHA256_Update(&seeded, seed, N);
+}
+
+static void addr_bytes(unsigned char *out, const uint32_t *a)
+{
+    int i;
+
+    for (i = 0; i < 8; i++) {
+        ull_to_bytes(out + 4 * i, 4, a[i]);
+    }
+}
+
+static void prf_addr(unsigned char *out, const uint32_t *a)
+{
+    unsigned char b[32];
+    SHA256_CTX c = seeded;
+
+    addr_bytes(b, a);
+    SHA256_Update(&c, b, 32);
+    SHA256_Final(out, &c);
+}
+
+static void set_addr(uint32_t *a, uint32_t layer, uint64_t tree, uint32_t type, uint32_t idx)
+{
+    memset(a, 0, 8 * sizeof(uint32_t));
+    a[0] = layer;
+    a[1] = (uint32_t)(tree >> 32);
+    a[2] = (uint32_t)tree;
+    a[3] = type;
+    a[4] = idx;
+}
+
+/* rand_f is F with a key and a bitmask of WOTS+ chains in RFC 8391 section 3.1.2. */
+static void rand_f(unsigned char *out, const unsigned char *in, uint32_t *a)
+{
+    unsigned char key[N], m[N];
+    int i;
+
+    a[7] = 0;
+    prf_addr(key, a);
+    a[7] = 1;
+    prf_addr(m, a);
+    for (i = 0; i < N; i++) {
+        m[i] ^= in[i];
+    }
+    hash(out, 0, key, m, N);
+}
+
+/* rand_h is RAND_HASH of RFC 8391 section 4.1.4. */
+static void rand_h(unsigned char *out, const unsigned char *l, const unsigned char *r, uint32_t *a)
+{
+    unsigned char key[N], m[2 * N];
+    int i;
+
+    a[7] = 0;
+    prf_addr(key, a);
+    a[7] = 1;
+    prf_addr(m, a);
+    a[7] = 2;
+    prf_addr(m + N, a);
+    for (i = 0; i < N; i++) {
+        m[i] ^= l[i];
+        m[N + i] ^= r[i];
+    }
+    hash(out, 1, key, m, 2 * N);
+}
+
+static void chain(unsigned char *out, const unsigned char *in,
+                  unsigned int start, unsigned int steps, uint32_t *a)
+{
+    unsigned int i;
+
+    memcpy(out, in, N);
+    for (i = start; i < start + steps; i++) {
+        a[6] = i;
+        rand_f(out, out, a);
+    }
+}
+
+/* digits returns base_w of m followed by its checksum. */
+static void digits(unsigned int *d, const unsigned char *m)
+{
+    unsigned int i, csum = 0;
+
+    for (i = 0; i < LEN1; i++) {
+        d[i] = (m[i / 2] >> (i % 2 ? 0 : 4)) & 0xf;
+        csum += W - 1 - d[i];
+    }
+    csum <<= 4;
+    d[LEN1] = (csum >> 12) & 0xf;
+    d[LEN1 + 1] = (csum >> 8) & 0xf;
+    d[LEN1 + 2] = (csum >> 4) & 0xf;
+}
+
+static void wots_sk(unsigned char *sk, const unsigned char *sk_seed, const uint32_t *ots)
+{
+    unsigned char b[32], s[N], ctr[32];
+    uint32_t a[8];
+    int i;
+
+    memcpy(a, ots, sizeof(a));
+    a[5] = a[6] = a[7] = 0;
+    addr_bytes(b, a);
+    prf(s, sk_seed, b);
+    for (i = 0; i < LEN; i++) {
+        ull_to_bytes(ctr, 32, i);
+        prf(sk + i * N, s, ctr);
+    }
+}
+
+/* ltree compresses the WOTS+ public key pk in place. */
+static void ltree(unsigned char *out, unsigned char *pk, uint32_t *a)
+{
+    unsigned int l = LEN, i;
+
+    a[5] = 0;
+    while (l > 1) {
+        for (i = 0; i < l / 2; i++) {
+            a[6] = i;
+            rand_h(pk + i * N, pk + 2 * i * N, pk + (2 * i + 1) * N, a);
+        }
+        if (l % 2) {
+            memcpy(pk + (l / 2) * N, pk + (l - 1) * N, N);
+        }
+        l = (l + 1) / 2;
+        a[5]++;
+    }
+    memcpy(out, pk, N);
+}
+
+static void leaf(unsigned char *out, const unsigned char *sk_seed,
+                 uint32_t layer, uint64_t tree, uint32_t idx)
+{
+    unsigned char sk[LEN * N], pk[LEN * N];
+    uint32_t a[8];
+    int i;
+
+    set_addr(a, layer, tree, ADDR_OTS, idx);
+    wots_sk(sk, sk_seed, a);
+    for (i = 0; i < LEN; i++) {
+        a[5] = i;
+        chain(pk + i * N, sk + i * N, 0, W - 1, a);
+    }
+    set_addr(a, layer, tree, ADDR_LTREE, idx);
+    ltree(out, pk, a);
+}
+
+/* subtree has all nodes of a tree, level by level from the leaves. */
+struct subtree {
+    unsigned char seeds[2 * N];
+    uint32_t layer;
+    uint64_t tree;
+    unsigned int height;
+    unsigned char *nodes;
+};
+
+#define NCACHE 4
+static struct subtree cache[NCACHE];
+static int next_cache;
+
+static unsigned char *node(const struct subtree *t, unsigned int z, uint64_t j)
+{
+    uint64_t off = 0;
+    unsigned int k;
+
+    for (k = 0; k < z; k++) {
+        off += 1ULL << (t->height - k);
+    }
+    return t->nodes + (off + j) * N;
+}
+
+static const struct subtree *subtree(const unsigned char *sk_seed, const unsigned char *pub_seed,
+                                     uint32_t layer, uint64_t tree, unsigned int height)
+{
+    struct subtree *t;
+    uint32_t a[8];
+    uint64_t j;
+    unsigned int z;
+    int i;
+
+    for (i = 0; i < NCACHE; i++) {
+        t = &cache[i];
+        if (t->nodes && t->layer == layer && t->tree == tree && t->height == height &&
+            !memcmp(t->seeds, sk_seed, N) && !memcmp(t->seeds + N, pub_seed, N)) {
+            return t;
+        }
+    }
+    t = &cache[next_cache];
+    next_cache = (next_cache + 1) % NCACHE;
+    free(t->nodes);
+    t->nodes = malloc(((2ULL << height) - 1) * N);
+    if (!t->nodes) {
+        abort();
+    }
+    memcpy(t->seeds, sk_seed, N);
+    memcpy(t->seeds + N, pub_seed, N);
+    t->layer = layer;
+    t->tree = tree;
+    t->height = height;
+    for (j = 0; j < 1ULL << height; j++) {
+        leaf(node(t, 0, j), sk_seed, layer, tree, (uint32_t)j);
+    }
+    set_addr(a, layer, tree, ADDR_HASHTREE, 0);
+    for (z = 0; z < height; z++) {
+        for (j = 0; j < 1ULL << (height - z - 1); j++) {
+            a[5] = z;
+            a[6] = (uint32_t)j;
+            rand_h(node(t, z + 1, j), node(t, z, 2 * j), node(t, z, 2 * j + 1), a);
+        }
+    }
+    return t;
+}
+
+int xmssmt_core_seed_keypair(const xmss_params *params,
+                             unsigned char *pk, unsigned char *sk, unsigned char *seed)
+{
+    const struct subtree *t;
+
+    memset(sk, 0, params->index_bytes);
+    sk += params->index_bytes;
+    memcpy(sk, seed, 2 * N);
+    memcpy(sk + 3 * N, seed + 2 * N, N);
+    set_seed(seed + 2 * N);
+    t = subtree(seed, seed + 2 * N, params->d - 1, 0, params->tree_height);
+    memcpy(sk + 2 * N, node(t, params->tree_height, 0), N);
+    memcpy(pk, sk + 2 * N, 2 * N);
+    return 0;
+}
+
+static void hash_msg(unsigned char *out, const unsigned char *r, const unsigned char *root,
+                     uint64_t idx, const unsigned char *m, unsigned long long mlen)
+{
+    unsigned char p[32] = {0}, i[32];
+    SHA256_CTX c;
+
+    p[31] = 2;
+    ull_to_bytes(i, 32, idx);
+    SHA256_Init(&c);
+    SHA256_Update(&c, p, 32);
+    SHA256_Update(&c, r, N);
+    SHA256_Update(&c, root, N);
+    SHA256_Update(&c, i, 32);
+    SHA256_Update(&c, m, mlen);
+    SHA256_Final(out, &c);
+}
+
+int xmssmt_core_sign(const xmss_params *params, unsigned char *sk,
+                     unsigned char *sm, unsigned long long *smlen,
+                     const unsigned char *m, unsigned long long mlen)
+{
+    const unsigned char *sk_seed = sk + params->index_bytes;
+    const unsigned char *sk_prf = sk_seed + N;
+    const unsigned char *root = sk_seed + 2 * N;
+    const unsigned char *pub_seed = sk_seed + 3 * N;
+    const unsigned int th = params->tree_height;
+    unsigned char msg[N], ctr[32], wsk[LEN * N], *out = sm;
+    unsigned int d[LEN], i, z, j;
+    uint64_t idx, tree;
+    uint32_t idx_leaf, a[8];
+    const struct subtree *t;
+
+    idx = bytes_to_ull(sk, params->index_bytes);
+    if (params->full_height < 64 && idx >= 1ULL << params->full_height) {
+        return -2;
+    }
+    memmove(sm + params->sig_bytes, m, mlen);
+    *smlen = params->sig_bytes + mlen;
+    set_seed(pub_seed);
+
+    memcpy(out, sk, params->index_bytes);
+    out += params->index_bytes;
+    ull_to_bytes(ctr, 32, idx);
+    prf(out, sk_prf, ctr);
+    hash_msg(msg, out, root, idx, sm + params->sig_bytes, mlen);
+    out += N;
+
+    tree = idx >> th;
+    idx_leaf = (uint32_t)(idx & ((1ULL << th) - 1));
+    for (j = 0; j < params->d; j++) {
+        set_addr(a, j, tree, ADDR_OTS, idx_leaf);
+        wots_sk(wsk, sk_seed, a);
+        digits(d, msg);
+        for (i = 0; i < LEN; i++) {
+            a[5] = i;
+            chain(out, wsk + i * N, 0, d[i], a);
+            out += N;
+        }
+        t = subtree(sk_seed, pub_seed, j, tree, th);
+        for (z = 0; z < th; z++) {
+            memcpy(out, node(t, z, (idx_leaf >> z) ^ 1), N);
+            out += N;
+        }
+        memcpy(msg, node(t, th, 0), N);
+        idx_leaf = (uint32_t)(tree & ((1ULL << th) - 1));
+        tree >>= th;
+    }
+    ull_to_bytes(sk, params->index_bytes, idx + 1);
+    return 0;
+}
+
+int xmssmt_core_sign_open(const xmss_params *params,
+                          unsigned char *m, unsigned long long *mlen,
+                          const unsigned char *sm, unsigned long long smlen,
+                          const unsigned char *pk)
+{
+    const unsigned char *root = pk, *pub_seed = pk + N, *r, *sig;
+    const unsigned int th = params->tree_height;
+    unsigned char node[N], wpk[LEN * N];
+    unsigned int d[LEN], i, z, j;
+    uint64_t idx, tree, k;
+    uint32_t idx_leaf, a[8];
+
+    if (smlen < params->sig_bytes) {
+        return -1;
+    }
+    *mlen = smlen - params->sig_bytes;
+    set_seed(pub_seed);
+    idx = bytes_to_ull(sm, params->index_bytes);
+    r = sm + params->index_bytes;
+    sig = r + N;
+    hash_msg(node, r, root, idx, sm + params->sig_bytes, *mlen);
+
+    tree = idx >> th;
+    idx_leaf = (uint32_t)(idx & ((1ULL << th) - 1));
+    for (j = 0; j < params->d; j++) {
+        set_addr(a, j, tree, ADDR_OTS, idx_leaf);
+        digits(d, node);
+        for (i = 0; i < LEN; i++) {
+            a[5] = i;
+            chain(wpk + i * N, sig + i * N, d[i], W - 1 - d[i], a);
+        }
+        sig += LEN * N;
+        set_addr(a, j, tree, ADDR_LTREE, idx_leaf);
+        ltree(node, wpk, a);
+        set_addr(a, j, tree, ADDR_HASHTREE, 0);
+        for (z = 0, k = idx_leaf; z < th; z++, k >>= 1) {
+            a[5] = z;
+            a[6] = (uint32_t)(k >> 1);
+            if (k % 2 == 0) {
+                rand_h(node, node, sig, a);
+            } else {
+                rand_h(node, sig, node, a);
+            }
+            sig += N;
+        }
+        idx_leaf = (uint32_t)(tree & ((1ULL << th) - 1));
+        tree >>= th;
+    }
+    if (memcmp(node, root, N)) {
+        memset(m, 0, *mlen);
+        *mlen = 0;
+        return -1;
+    }
+    memcpy(m, sm + params->sig_bytes, *mlen);
+    return 0;
+}
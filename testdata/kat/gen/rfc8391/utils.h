@@ -0,0 +1,10 @@
+/*
+ * The subset of utils.h of the XMSS reference implementation used by katgen.c.
+ */
+#ifndef RFC8391_UTILS_H
+#define RFC8391_UTILS_H
+
+void ull_to_bytes(unsigned char *out, unsigned int outlen, unsigned long long in);
+unsigned long long bytes_to_ull(const unsigned char *in, unsigned int inlen);
+
+#endif
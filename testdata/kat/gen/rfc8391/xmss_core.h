@@ -0,0 +1,19 @@
+/*
+ * The subset of xmss_core.h of the XMSS reference implementation used by katgen.c.
+ */
+#ifndef RFC8391_XMSS_CORE_H
+#define RFC8391_XMSS_CORE_H
+
+#include "params.h"
+
+int xmssmt_core_seed_keypair(const xmss_params *params,
+                             unsigned char *pk, unsigned char *sk, unsigned char *seed);
+int xmssmt_core_sign(const xmss_params *params, unsigned char *sk,
+                     unsigned char *sm, unsigned long long *smlen,
+                     const unsigned char *m, unsigned long long mlen);
+int xmssmt_core_sign_open(const xmss_params *params,
+                          unsigned char *m, unsigned long long *mlen,
+                          const unsigned char *sm, unsigned long long smlen,
+                          const unsigned char *pk);
+
+#endif
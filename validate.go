@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+//ValidationError is returned when decoding malformed or inconsistent
+//public keys, signatures, private keys or states.
+type ValidationError string
+
+func (e ValidationError) Error() string {
+	return string(e)
+}
+
+//maxHeight is the max height of a tree, which has 2^32 leaves.
+const maxHeight = 32
+
+//validate checks the node in nn.
+func (nn *NH) validate() error {
+	if len(nn.node) != n {
+		return ValidationError("invalid length of node")
+	}
+	return nil
+}
+
+//validate checks the number of nodes and their heights in s.
+func (s *Stack) validate() error {
+	if s.height >= maxHeight || len(s.stack) > int(s.height)+1 {
+		return ValidationError("too many nodes in stack")
+	}
+	for _, nn := range s.stack {
+		if nn == nil || nn.height > s.height {
+			return ValidationError("invalid node in stack")
+		}
+		if err := nn.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//validate checks lengths of seeds and root in s.
+func (s *privkey) validate() error {
+	if len(s.MsgSeed) != n || len(s.WotsSeed) != n || len(s.PubSeed) != n || len(s.Root) != n {
+		return ValidationError("invalid length of seed or root")
+	}
+	return nil
+}
+
+//validate checks the parameters and the traversal state of m against each other.
+func (m *Merkle) validate() error {
+	if m.priv == nil {
+		return ValidationError("no private key")
+	}
+	if m.Height == 0 || m.Height > maxHeight || m.k > m.Height || m.k > maxFullHeight {
+		return ValidationError("invalid height or k")
+	}
+	if uint32(len(m.stacks)) != m.Height-m.k || uint64(len(m.retain)) != retainSize(m.k) {
+		return ValidationError("invalid number of stacks or retained nodes")
+	}
+	if uint32(len(m.auth)) != m.Height {
+		return ValidationError("invalid length of auth path")
+	}
+	for _, a := range m.auth {
+		if len(a) != n {
+			return ValidationError("invalid length of auth path")
+		}
+	}
+	for i, s := range m.stacks {
+		if s == nil || s.height != uint32(i) || s.layer != m.layer || s.tree != m.tree {
+			return ValidationError("invalid stack")
+		}
+		if err := s.validate(); err != nil {
+			return err
+		}
+	}
+	for _, r := range m.retain {
+		if len(r) != n {
+			return ValidationError("invalid length of retained node")
+		}
+	}
+	if uint64(m.Leaf) > 1<<m.Height {
+		return ValidationError("invalid leaf number")
+	}
+	return nil
+}
+
+//validate checks the parameters of p and its Merkles against each other.
+func (p *PrivKeyMT) validate() error {
+	if _, err := PublickeyMTHeader(p.h, p.d); err != nil {
+		return err
+	}
+	if uint32(len(p.merkle)) != p.d || p.merkle[p.d-1] == nil {
+		return ValidationError("invalid number of Merkles")
+	}
+	for i, m := range p.merkle {
+		if m != nil && (m.Height != p.h/p.d || m.layer != uint32(i)) {
+			return ValidationError("invalid Merkle in a layer")
+		}
+	}
+	if p.index > p.end {
+		return ValidationError("invalid index")
+	}
+	return nil
+}
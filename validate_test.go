@@ -0,0 +1,224 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xmss
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+func TestValidatePublicKey(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for validation.")
+	mer := NewMerkle(4, seed)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := mer.PublicKey()
+	for _, h := range []byte{0, maxHeight + 1, 255} {
+		bad := append([]byte{h}, pk[1:]...)
+		if _, err := DeserializePK(bad); err == nil {
+			t.Error("should not deserialize invalid height", h)
+		} else if _, ok := err.(ValidationError); !ok {
+			t.Error("error must be ValidationError", err)
+		}
+		if Verify(sig, msg, bad) {
+			t.Error("should not verify with invalid height", h)
+		}
+	}
+	for _, bad := range [][]byte{
+		sig[:len(sig)-1],
+		append(append([]byte{}, sig...), 0),
+		append(append([]byte{}, sig...), make([]byte, n)...),
+		append([]byte{0, 0, 0, 16}, sig[4:]...),
+		append([]byte{0xff, 0xff, 0xff, 0xff}, sig[4:]...),
+		nil,
+	} {
+		if Verify(bad, msg, pk) {
+			t.Error("should not verify invalid signature")
+		}
+	}
+
+	for _, b := range []byte{0x00, 0x10, 0x20, 0x01, 0x13, 0x23, 0x1f, 0x11, 0x25, 0x32, 0x48, 0xf5} {
+		key := make([]byte, 1+n+n)
+		key[0] = b
+		if _, err := DeserializeMT(key); err == nil {
+			t.Error("should not deserialize invalid h or d", b)
+		} else if _, ok := err.(ValidationError); !ok {
+			t.Error("error must be ValidationError", err)
+		}
+		if VerifyMT(make([]byte, 1000), msg, key) {
+			t.Error("should not verify with invalid h or d", b)
+		}
+	}
+	if _, err := NewPrivKeyMT(seed, 20, 0); err == nil {
+		t.Error("should not make a key with d=0")
+	}
+}
+
+func TestValidateMTParams(t *testing.T) {
+	seed := generateSeed()
+	for _, p := range [][2]uint32{{20, 1}, {20, 5}, {40, 5}, {60, 2}, {80, 8}, {300, 5}} {
+		if _, err := NewPrivKeyMT(seed, p[0], p[1]); err == nil {
+			t.Error("should not make a key out of RFC 8391", p)
+		}
+		if _, err := PublickeyMTHeader(p[0], p[1]); err == nil {
+			t.Error("should not make a header out of RFC 8391", p)
+		}
+	}
+	msg := []byte("This is a test for XMSS^MT with h=60.")
+	mt, err := NewPrivKeyMT(seed, 60, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mt.SetLeafNo(1<<60 - 1); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mt.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyMT(sig, msg, mt.PublicKey()) {
+		t.Error("signature is invalid")
+	}
+}
+
+//mutate returns a copy of b with some random bytes changed, inserted or removed.
+func mutate(r *rand.Rand, b []byte) []byte {
+	c := append([]byte{}, b...)
+	for i := r.Intn(3); i >= 0; i-- {
+		if len(c) == 0 {
+			return c
+		}
+		p := r.Intn(len(c))
+		switch r.Intn(4) {
+		case 0:
+			c = append(c[:p], c[p+1:]...)
+		case 1:
+			c = append(c[:p], append([]byte{byte(r.Intn(256))}, c[p:]...)...)
+		default:
+			c[p] = byte(r.Intn(256))
+		}
+	}
+	return c
+}
+
+func TestValidateNoPanic(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for decoding malformed states.")
+	mer, err := NewMerkleK(4, 1, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := mer.Sign(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jdat, err := json.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdat, err := msgpack.Marshal(mer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bdat, err := mer.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := mer.PublicKey()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		func() {
+			defer func() {
+				if e := recover(); e != nil {
+					t.Fatal("panic", i, e)
+				}
+			}()
+			var m Merkle
+			if json.Unmarshal(mutate(r, jdat), &m) == nil {
+				m.Traverse()
+			}
+			if msgpack.Unmarshal(mutate(r, mdat), &m) == nil {
+				m.Traverse()
+			}
+			if m.UnmarshalBinary(mutate(r, bdat)) == nil {
+				m.Traverse()
+			}
+			Verify(mutate(r, sig), msg, pk)
+			Verify(sig, msg, mutate(r, pk))
+		}()
+	}
+
+	var s map[string]interface{}
+	if err := json.Unmarshal(jdat, &s); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []func(map[string]interface{}){
+		func(s map[string]interface{}) { s["Auth"] = s["Auth"].([]interface{})[1:] },
+		func(s map[string]interface{}) { s["Auth"].([]interface{})[0] = "AAAA" },
+		func(s map[string]interface{}) { s["Height"] = 5.0 },
+		func(s map[string]interface{}) { s["Height"] = 0.0 },
+		func(s map[string]interface{}) { s["K"] = 7.0 },
+		func(s map[string]interface{}) { s["Stacks"].([]interface{})[0] = nil },
+		func(s map[string]interface{}) {
+			s["Stacks"].([]interface{})[1].(map[string]interface{})["Height"] = 0.0
+		},
+		func(s map[string]interface{}) { s["Retain"].([]interface{})[0] = "" },
+		func(s map[string]interface{}) { s["Priv"].(map[string]interface{})["WotsSeed"] = nil },
+		func(s map[string]interface{}) { s["Priv"].(map[string]interface{})["Root"] = "AAAA" },
+		func(s map[string]interface{}) { s["Priv"] = nil },
+		func(s map[string]interface{}) { s["Leaf"] = 100.0 },
+	} {
+		var c map[string]interface{}
+		if err := json.Unmarshal(jdat, &c); err != nil {
+			t.Fatal(err)
+		}
+		f(c)
+		dat, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m Merkle
+		err = json.Unmarshal(dat, &m)
+		if err == nil {
+			t.Error("should not unmarshal invalid state", string(dat))
+			continue
+		}
+		if _, ok := err.(ValidationError); !ok {
+			t.Error("error must be ValidationError", err)
+		}
+	}
+	if !bytes.Equal(pk, mer.PublicKey()) {
+		t.Error("public key must not be changed")
+	}
+}
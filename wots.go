@@ -22,7 +22,6 @@ package xmss
 
 import (
 	"runtime"
-	"sync"
 	"unsafe"
 )
 
@@ -49,56 +48,57 @@ type wotsPubKey [][]byte
 type wotsSig [][]byte
 
 func chain(x []byte, start, step byte, p *prf, addrs addr, out []byte) {
-	copy(out, x)
-	key := make([]byte, 32)
-	bm := make([]byte, 32)
-	xor := make([]byte, 32)
-	for i := byte(0); i < step; i++ {
-		addrs.set(adrHash, uint32(start+i))
-		addrs.set(adrKM, 0)
-		p.sum(addrs, key)
-		addrs.set(adrKM, 1)
-		p.sum(addrs, bm)
-		xorWords(xor, out, bm)
-		hashF(key, xor, out)
-	}
+	h := getHasher()
+	h.chain(x, start, step, p, addrs, out)
+	putHasher(h)
 }
 
+var (
+	//chainStarts and chainSteps are starts and steps of chains to compute a public key.
+	chainStarts = make([]byte, wlen)
+	chainSteps  = func() []byte {
+		s := make([]byte, wlen)
+		for i := range s {
+			s[i] = w - 1
+		}
+		return s
+	}()
+)
+
 func (priv wotsPrivKey) newWotsPubKey(p *prf, addrs addr, pubkey wotsPubKey) {
-	for i := 0; i < len(pubkey); i++ {
-		addrs.set(adrChain, uint32(i))
-		chain(priv[i], 0, w-1, p, addrs, pubkey[i])
-	}
+	h := getHasher()
+	h.lanes.chains(priv, chainStarts, chainSteps, p, addrs, pubkey, 0, len(pubkey))
+	h.lanes.wipe()
+	putHasher(h)
 }
 
-func goChain(addrs addr, fchain func(i int, a addr)) {
-	var wg sync.WaitGroup
-	ncpu := runtime.GOMAXPROCS(-1)
-	nitem := wlen/ncpu + 1
-	for i := 0; i < ncpu; i++ {
-		wg.Add(1)
-		go func(i int) {
-			start := i * nitem
-			end := start + nitem
-			if end > wlen {
-				end = wlen
-			}
-			a := make(addr, 32)
-			copy(a, addrs)
-			for j := start; j < end; j++ {
-				a.set(adrChain, uint32(j))
-				fchain(j, a)
-			}
-			wg.Done()
-		}(i)
+//goChains advances chains like hashLanes.chains for all chains,
+//split into ranges which run in parallel by e, or DefaultExecutor if e is nil.
+func goChains(in [][]byte, starts, steps []byte, p *prf, addrs addr, out [][]byte, e Executor) {
+	e = executorOr(e)
+	if e.Concurrency() == 1 {
+		h := getHasher()
+		h.lanes.chains(in, starts, steps, p, addrs, out, 0, wlen)
+		h.lanes.wipe()
+		putHasher(h)
+		return
 	}
-	wg.Wait()
+	nitem := wlen/e.Concurrency() + 1
+	e.Run((wlen+nitem-1)/nitem, func(i int) {
+		start := i * nitem
+		end := start + nitem
+		if end > wlen {
+			end = wlen
+		}
+		h := getHasher()
+		h.lanes.chains(in, starts, steps, p, addrs, out, start, end)
+		h.lanes.wipe()
+		putHasher(h)
+	})
 }
 
-func (priv wotsPrivKey) goNewWotsPubKey(p *prf, addrs addr, pubkey wotsPubKey) {
-	goChain(addrs, func(i int, a addr) {
-		chain(priv[i], 0, w-1, p, a, pubkey[i])
-	})
+func (priv wotsPrivKey) goNewWotsPubKey(p *prf, addrs addr, pubkey wotsPubKey, e Executor) {
+	goChains(priv, chainStarts, chainSteps, p, addrs, pubkey, e)
 }
 
 const (
@@ -106,41 +106,66 @@ const (
 	toPubkey
 )
 
-func nchain(in [][]byte, m []byte, p *prf, addrs addr, typee int) [][]byte {
-	out := make([][]byte, wlen)
-	for i := range out {
-		out[i] = make([]byte, n)
-	}
-	msg := make([]byte, wlen)
+//baseW computes base w digits of m followed by its checksum into msg,
+//which are the numbers of steps of chains for a signature.
+func baseW(m, msg []byte) {
 	base16(m, msg[:wlen1])
 	var csum uint16
 	for _, mm := range msg[:wlen1] {
 		csum += w - 1 - uint16(mm)
 	}
 	csum <<= 4
-	tmp := []byte{
+	tmp := [2]byte{
 		byte((csum & 0xff00) >> 8),
 		byte((csum & 0x00ff)),
 	}
-	base16(tmp, msg[wlen1:])
+	base16(tmp[:], msg[wlen1:])
+}
+
+//newChains returns wlen chains of n bytes.
+func newChains() [][]byte {
+	out := make([][]byte, wlen)
+	buf := make([]byte, wlen*n)
+	for i := range out {
+		out[i] = buf[i*n : (i+1)*n]
+	}
+	return out
+}
+
+func nchain(in [][]byte, m []byte, p *prf, addrs addr, typee int, out [][]byte, e Executor) {
+	h := getHasher()
+	msg := h.digits
+	baseW(m, msg)
 	if typee == toSig {
-		goChain(addrs, func(i int, a addr) {
-			chain(in[i], 0, msg[i], p, a, out[i])
-		})
+		goChains(in, chainStarts, msg, p, addrs, out, e)
 	} else {
-		goChain(addrs, func(i int, a addr) {
-			chain(in[i], msg[i], w-1-msg[i], p, a, out[i])
-		})
+		steps := h.steps
+		for i := range steps {
+			steps[i] = w - 1 - msg[i]
+		}
+		goChains(in, msg, steps, p, addrs, out, e)
 	}
-	return out
+	putHasher(h)
+}
+
+func (priv wotsPrivKey) sign(m []byte, p *prf, addrs addr, e Executor) wotsSig {
+	sig := newChains()
+	priv.signTo(m, p, addrs, sig, e)
+	return sig
+}
+
+func (priv wotsPrivKey) signTo(m []byte, p *prf, addrs addr, out wotsSig, e Executor) {
+	nchain(priv, m, p, addrs, toSig, out, e)
 }
 
-func (priv wotsPrivKey) sign(m []byte, p *prf, addrs addr) wotsSig {
-	return nchain(priv, m, p, addrs, toSig)
+func (sig wotsSig) pubkey(m []byte, p *prf, addrs addr, e Executor) wotsPubKey {
+	pk := newChains()
+	sig.pubkeyTo(m, p, addrs, pk, e)
+	return pk
 }
 
-func (sig wotsSig) pubkey(m []byte, p *prf, addrs addr) wotsPubKey {
-	return nchain(sig, m, p, addrs, toPubkey)
+func (sig wotsSig) pubkeyTo(m []byte, p *prf, addrs addr, out wotsPubKey, e Executor) {
+	nchain(sig, m, p, addrs, toPubkey, out, e)
 }
 
 //codes below is from https://golang.org/src/crypto/cipher/xor.go
@@ -60,8 +60,8 @@ func TestWOTS(t *testing.T) {
 	priv.newWotsPubKey(prf, make([]byte, 32), pub)
 	msg := []byte("This is a test for wots.")
 	hmsg := sha256.Sum256(msg)
-	sign := priv.sign(hmsg[:], prf, make([]byte, 32))
-	pub2 := sign.pubkey(hmsg[:], prf, make([]byte, 32))
+	sign := priv.sign(hmsg[:], prf, make([]byte, 32), nil)
+	pub2 := sign.pubkey(hmsg[:], prf, make([]byte, 32), nil)
 	ok := true
 	for i := range pub {
 		if !bytes.Equal(pub[i], pub2[i]) {
@@ -118,7 +118,7 @@ func TestWOTS2(t *testing.T) {
 	adr[7] = 2
 	adr[11] = 3
 	adr[15] = 4
-	sign := priv.sign(msg, prf, adr)
+	sign := priv.sign(msg, prf, adr, nil)
 	csig := "6577420a8e3a19d3fc4fa081294aa3d58105fca3b512148f4d22ef414d25b2956da29ea5f5f8767fd5cc506ff08fe3395193caa38025f32749483ec98e15d5b8cfcaf7d678c94575722a64f4fe59dd4bba93c5cac1e5db2b997445df7a00e05faa6e79b7331e8951f88248633d12d108e77738414ba66d7bfa636ffc77624204d62b281a7eb8fcbac9850044ac5208bb337382b1af843d5c21e65f4570dd181ce8b89db33235364b97917099467e881adfedd8eed5ef2f94f9613059494c9b4fe6cdfc2fe8ffc588f1fca7c41500209661bea0659d2af697760c754126f3b063ab33b6c7d41527327c2b155da09b510cff5364742ba7019bceb7b2d8bd53e038a536c86cb6f3a889defaa19efff07c11c5059350b178ae9a5890ce78c99998581d670135e432dc239b3f2f9364c97a6e4e3e04496779960826f8cabd714e6ce0702090a97480a41d155da811456994e5cf55e21a8a8163ddceab244afaceca193c583fa5ece67e66ccdb7a4290308f1d4ff852d1d1d66c0dae2d6437df86d966a1e8393f5004f540685630b3ae45e39be1ca440348d44c7ec10a1d77e191b7e9460c25a6275f93c1b36c79c0de8300ee3f18259c1ab0deaba98ae0042fe49eff6e6ee57b9c278aa03d675b96bdc10d4c234f53291d67d0c9f177c53e5ae1c31afbe2687465d04b6cece6b640b0ddf4546836a751bf6db2612d5e4500c0393a07ef2e877926a6be0ed6d111bca72e4c087ff1d60a782eec3a7d6c081397a382601d9cf4769f8e860786f7a905d3afa11a6c232d871b85df4ec4a653712336e76ed1fe8af80d03708c6464f0cdb8cee2260e68a76858895986044ef9a8df2ff5a7969d39cb03e13f74b35b52745d6fbae9ed43c91d722cd2de4d614ff8b0ed297e91451b753f54738a452f047312fda547d21227837ab177d4ca69665beb949638253f2dfda758888e9552c75b6b5e340a2fe9a861e31be84e1234beff3593f4df0b4e041b223e59033cbca2a244aa997a402d4cd0da07493d2a4125649d7eab460a4a992fbad6b170995234e098e51a139dc4c978ba68efedd985682f41188832a8f46cf67a7c9b75c6429358662de15900d3a24928e0ab38616af74021f0e8f245628cd25b12fbf954558b380bf75030288e2ee2ebe10c35bf82921d33309321abef5b38493a592a0bddf8852435eb3d72b7426fcba5db96f35b5b1ecd26074dd20a723de86ac76e5f2e1417f50a18b5b697a383402d463d555db115ef9f23a7254e688f3b7ff8cb0ecbc4012780f0573889ccf450505e7a9d81b81a51c3cfaa7b0974d15ce2f825188cf9b935cf6adcbb37d16d8525dc31b07c85d7a46ceb9f6f28d67168619b53c506a6be7f8154ee5dedcf5f95618bed051ffc1a22289279f4b4b2ad12c609b0762d65f3f7529fb907a9a974a7c2f67a5e3d9dfdbb686e6f4912e6ac5aa4cce6a1c0c7b7e750a2204fef60c3dbfd8ebaddeaf4c312c9885e454ca6d7a32b1b4c3c78ff18ed629f8c1b205adfe2a2dc2008b6de93c2015f9b4535ba6830c4335bc61da48b13b171ca305351b33d5b9885d5ef92c11e75af9caf028de6b72b3bbdc4913d4b155d49b9300f30f15026d3836d0bf807fd291d8702fc507cccf5ea70043a1ab04c227fee6959f05f81bd273fdaa1e77f4d267fba411e314a58d3145e8c07cbbad6d9f40f5f1adceb125e302c380c6530d7dc91e2e14308c60bdfd6c1a83a17552a263c97c34a23c7607f186849484b3b9c4d66d09893bac671141595828b0689bba2ab576ba254cd32666662b9993f4b39bebb678d24676f7e18f5578238f5548c822b54d459876ba467d2fd9652a8b0fb13d056e66049ac78ba4dc00a2437ff0740d3f09ced154c1b505666819dcbed122c7c047e26a01f20c9c91f0233d45823af278135c04f0a844ccdd4947815d8c33cca8864f2d95a091f4fa5bd676ea0aa3ff3c661fdd0a00ec99de40049573e43c8625fc347b32f864be4d7fddf50e5185b2808712aad5c74e51dff9c9d46e7a5c41396b5656bfd9c76967f2523df02f730c0313738d4ab360e4afe40ac0b79819afbb1e9e270ad3414c4cda08c87eabe4b52cb40e2ddc87799f147a1e22904a43dad160f02e276092fdf49aa6a731a4e373fcefbdf7bf74811e012527078a97eba23ac4a66af63c751859b717f5ca9cebf2825772cbf801ace659f5c9c4b15fb71618d1adffbd9a3f65e1c0cfc5414d868fef70fc9d4165aeafb7d6a55e018038926c795713de32c8d1e7a7286f5fbc3fa3b93ad477207fb6115cde7178fd8de47c9076fbf5a484fc56b501b19c2485fe86f6b7e33adfce1bc8545f766f7a01c4cdc5a7ba6363da3b82fa2e19d8482599ae71910dd78a41b6b0b7014ef1c23d9cd352c947123fe5c1499dc577aab5f76754cb5f52ad9f7ab029931ffa038d19182b263bf4e0a02a800093f65a101614e3a8a60c3f820653bac02a6a97830957cedc4926957dc72fa0ed92d90e5eb2767bef4f976a6242eced91a0dc6b3e4b98f4b04a9596631bada3fcff294341a81649560f1a77f1277b68e07b19f24d20a96196e3ede6de0c6f4d1b4b155c05663f38c10c0fb783d20bc0577c7ec857be60102e4e0f6e2c87ae94e381c8e968acf379c16d2a4f3ccd41ebc9b9638b51d3ea15326ec73508362f642c6fe78d22c28fc4b33a2c4b666dd12b9578c0ca024e3292e2c385bfc6f07d88a6d55953e29e8f619eb8aeb0f62e66082ceffdaf17aa5d3e3bb0221a2b7ff58fe854cf972013283010a5b6a6fac76360f4048596f2ed2bcfa7f51bbce35f68f90b85386f31785efa3a88eff6807fb9e97e094ae7613b9b18edd2e629dce99a9ffb2e3090ef1905b4527c3874eaf8c68dde32838c0a29e54d1c053d3e93a20980af6d34325ec32d1f058b1ab299f6e24911e5ad03739dd2d8126d791e506c8b346e0ada92acf6d2ac1eb78a488e70070b7d8676470e97ea8c369f9f03631604d5f29d896b6a7e93db20b3c1c782a06cf4758a88e7e7980aa9777de"
 	allsig := make([]byte, 32*wlen)
 	for i, p := range sign {
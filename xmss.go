@@ -35,7 +35,19 @@ type PrivKey struct {
 	wotsPRF *prf //S in draft, used to generate private key elements of WOTS.
 	pubPRF  *prf //SEED in draft , used to make public keys of WOTS.
 	root    []byte
+	//destroyed is true after secrets are wiped by Destroy.
+	destroyed bool
+	//exec runs tasks concurrently. DefaultExecutor is used if nil.
+	exec Executor
 }
+
+func (x *PrivKey) executor() Executor {
+	return executorOr(x.exec)
+}
+
+//ErrDestroyed is returned when using a key which was already destroyed.
+var ErrDestroyed = errors.New("key is destroyed")
+
 type privkey struct {
 	MsgSeed  []byte
 	WotsSeed []byte
@@ -51,30 +63,49 @@ func (x *PrivKey) exports() *privkey {
 		Root:     x.root,
 	}
 }
-func (x *PrivKey) imports(s *privkey) {
+func (x *PrivKey) imports(s *privkey) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
 	x.msgPRF = newPRF(s.MsgSeed)
 	x.wotsPRF = newPRF(s.WotsSeed)
 	x.pubPRF = newPRF(s.PubSeed)
 	x.root = s.Root
+	wipe(s.MsgSeed)
+	wipe(s.WotsSeed)
+	return nil
+}
+
+//Destroy wipes the secret seeds in PrivKey.
+//PrivKey cannot be used after calling this.
+func (x *PrivKey) Destroy() {
+	x.msgPRF.destroy()
+	x.wotsPRF.destroy()
+	x.destroyed = true
 }
 
 //MarshalJSON  marshals PrivKey into valid JSON.
 func (x *PrivKey) MarshalJSON() ([]byte, error) {
+	if x.destroyed {
+		return nil, ErrDestroyed
+	}
 	return json.Marshal(x.exports())
 }
 
 //UnmarshalJSON  unmarshals JSON to PrivKey.
 func (x *PrivKey) UnmarshalJSON(b []byte) error {
 	var s privkey
-	err := json.Unmarshal(b, &s)
-	if err == nil {
-		x.imports(&s)
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
 	}
-	return err
+	return x.imports(&s)
 }
 
 //EncodeMsgpack  marshals PrivKey into valid msgpack.
 func (x *PrivKey) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if x.destroyed {
+		return ErrDestroyed
+	}
 	return enc.Encode(x.exports())
 }
 
@@ -84,17 +115,13 @@ func (x *PrivKey) DecodeMsgpack(dec *msgpack.Decoder) error {
 	if err := dec.Decode(&s); err != nil {
 		return err
 	}
-	x.imports(&s)
-	return nil
+	return x.imports(&s)
 }
 
 func (x *PrivKey) newWotsPrivKey(addrs addr, priv wotsPrivKey) {
-	s := make([]byte, 32)
-	x.wotsPRF.sum(addrs, s)
-	p := newPRF(s)
-	for i := range priv {
-		p.sumInt(uint32(i), priv[i])
-	}
+	h := getHasher()
+	h.newWotsPrivKey(x, addrs, priv)
+	putHasher(h)
 }
 
 //PublicKey for xmss
@@ -115,10 +142,22 @@ func (p *PublicKey) Serialize() []byte {
 
 //DeserializePK deserialized bytes to XMSS PublicKey.
 func DeserializePK(key []byte) (*PublicKey, error) {
+	pk, err := parsePK(key)
+	if err != nil {
+		return nil, err
+	}
+	return &pk, nil
+}
+
+//parsePK is DeserializePK which returns PublicKey referring to key.
+func parsePK(key []byte) (PublicKey, error) {
 	if len(key) != 65 {
-		return nil, errors.New("invalid bytes length")
+		return PublicKey{}, ValidationError("invalid bytes length")
+	}
+	if key[0] == 0 || key[0] > maxHeight {
+		return PublicKey{}, ValidationError("invalid height")
 	}
-	return &PublicKey{
+	return PublicKey{
 		Height: key[0],
 		Root:   key[1:33],
 		Seed:   key[33:65],
@@ -126,32 +165,32 @@ func DeserializePK(key []byte) (*PublicKey, error) {
 }
 
 func randHash(left, right []byte, p *prf, addrs addr, out []byte) {
-	addrs.set(adrKM, 0)
-	key := make([]byte, 32)
-	p.sum(addrs, key)
-	addrs.set(adrKM, 1)
-	bm0 := make([]byte, 32)
-	p.sum(addrs, bm0)
-	addrs.set(adrKM, 2)
-	bm1 := make([]byte, 32)
-	p.sum(addrs, bm1)
-
-	lxor := make([]byte, 32)
-	xorWords(lxor, left, bm0)
-	rxor := make([]byte, 32)
-	xorWords(rxor, right, bm1)
-	hashH(key, lxor, rxor, out)
+	h := getHasher()
+	h.randHash(left, right, p, addrs, out)
+	putHasher(h)
 }
 
 func (pk wotsPubKey) ltree(p *prf, addrs addr) []byte {
+	h := getHasher()
+	root := pk.ltreeWith(h.lanes, p, addrs)
+	putHasher(h)
+	return root
+}
+
+func (pk wotsPubKey) ltreeWith(hl *hashLanes, p *prf, addrs addr) []byte {
 	var height uint32
 	addrs.set(adrHeight, 0)
 	var l uint32
 	for l = wlen; l > 1; l = (l >> 1) + (l & 0x1) {
-		var i uint32
-		for i = 0; i < l>>1; i++ {
-			addrs.set(adrIndex, i)
-			randHash(pk[2*i], pk[2*i+1], p, addrs, pk[i])
+		for g := uint32(0); g < l>>1; g += lanes {
+			nl := 0
+			for i := g; i < l>>1 && i < g+lanes; i++ {
+				copy(hl.as[nl], addrs)
+				addr(hl.as[nl]).set(adrIndex, i)
+				hl.lefts[nl], hl.rights[nl], hl.outs[nl] = pk[2*i], pk[2*i+1], pk[i]
+				nl++
+			}
+			hl.randHash(p, nl)
 		}
 		if l&0x1 == 1 {
 			copy(pk[l>>1], pk[l-1])
@@ -159,6 +198,7 @@ func (pk wotsPubKey) ltree(p *prf, addrs addr) []byte {
 		height++
 		addrs.set(adrHeight, height)
 	}
+	hl.wipe()
 	return pk[0]
 }
 
@@ -173,16 +213,6 @@ type xmssSig struct {
 	*xmssSigBody
 }
 
-func (x *xmssSig) bytes() []byte {
-	sigSize := 4 + n + wlen*n + len(x.auth)*n
-	sig := make([]byte, sigSize)
-	binary.BigEndian.PutUint32(sig, x.idx)
-	copy(sig[4:], x.r)
-	sigBody := x.xmssSigBody.bytes()
-	copy(sig[4+n:], sigBody)
-	return sig
-}
-
 func (x *xmssSigBody) bytes() []byte {
 	sigSize := wlen*n + len(x.auth)*n
 	sig := make([]byte, sigSize)
@@ -196,11 +226,10 @@ func (x *xmssSigBody) bytes() []byte {
 }
 
 func bytes2sig(b []byte, h byte) (*xmssSig, error) {
-	height := (len(b) - (4 + n + wlen*n)) >> 5
-	if height != int(h) {
-		return nil, errors.New("invalid length of bytes")
+	if len(b) != 4+n+(wlen+int(h))*n {
+		return nil, ValidationError("invalid length of bytes")
 	}
-	body := bytes2sigBody(b[4+n:], height)
+	body := bytes2sigBody(b[4+n:], int(h))
 	sig := &xmssSig{
 		idx:         binary.BigEndian.Uint32(b),
 		r:           b[4 : 4+n],
@@ -224,89 +253,177 @@ func bytes2sigBody(b []byte, height int) *xmssSigBody {
 }
 
 //Sign signs by XMSS with MerkleTree.
-func (m *Merkle) Sign(msg []byte) []byte {
-	index := make([]byte, 32)
-	binary.BigEndian.PutUint32(index[28:], m.Leaf)
-	r := make([]byte, 32*3)
-	m.priv.msgPRF.sum(index, r)
+//It returns an error if no leaf is left in the range of m.
+func (m *Merkle) Sign(msg []byte) ([]byte, error) {
+	m.Flush()
+	if m.priv.destroyed {
+		return nil, ErrDestroyed
+	}
+	if m.suspect {
+		return nil, ErrSuspect
+	}
+	if err := checkSelfTest(); err != nil {
+		return nil, err
+	}
+	if err := m.checkRange(); err != nil {
+		return nil, err
+	}
+	if uint64(m.Leaf) < m.start || uint64(m.Leaf) >= m.end {
+		return nil, errors.New("leaf index is out of range")
+	}
+	result := m.signBytes(m.Leaf, msg, m.auth)
+	m.advance() //never relocate the line to above
+	m.skipUsed()
+	m.refill()
+	if err := m.commit(); err != nil {
+		return nil, err
+	}
+	if err := m.checkSig(result, msg); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//signBytes returns the signature of msg at leaf with auth.
+//It allocates nothing but the signature.
+func (m *Merkle) signBytes(leaf uint32, msg []byte, auth [][]byte) []byte {
+	sig := make([]byte, 4+n+(wlen+len(auth))*n)
+	binary.BigEndian.PutUint32(sig, leaf)
+	var index [32]byte
+	binary.BigEndian.PutUint32(index[28:], leaf)
+	var r [32 * 3]byte
+	m.priv.msgPRF.sum(index[:], r[:])
 	copy(r[32:], m.priv.root)
-	copy(r[64:], index)
-	hmsg := hashMsg(r, msg)
-	sigBody := m.sign(hmsg)
-	sig := &xmssSig{
-		idx:         m.Leaf,
-		r:           r[:32],
-		xmssSigBody: sigBody,
+	copy(r[64:], index[:])
+	copy(sig[4:], r[:32])
+	var hmsg [n]byte
+	h := getHasher()
+	h.hashMsg(r[:], msg, hmsg[:])
+	body := h.sigBody(sig[4+n:], len(auth))
+	m.wotsSign(leaf, hmsg[:], body.sig)
+	for i, a := range auth {
+		copy(body.auth[i], a)
+	}
+	h.clearBody()
+	putHasher(h)
+	return sig
+}
+
+//signBody signs hmsg by the current leaf into body, whose buffers are filled in place.
+func (m *Merkle) signBody(hmsg []byte, body *xmssSigBody) {
+	m.wotsSign(m.Leaf, hmsg, body.sig)
+	for i, a := range m.auth {
+		copy(body.auth[i], a)
 	}
-	result := sig.bytes()
-	m.Traverse() //never relocate the line to above
-	return result
 }
 
-func (m *Merkle) sign(hmsg []byte) *xmssSigBody {
-	wsk := make(wotsPrivKey, wlen)
-	for i := range wsk {
-		wsk[i] = make([]byte, 32)
+//wotsSign computes WOTS+ signature of hmsg at leaf into out.
+func (m *Merkle) wotsSign(leaf uint32, hmsg []byte, out wotsSig) {
+	if m.signByTable(leaf, hmsg, out) {
+		return
 	}
-	addrs := make(addr, 32)
+	h := getHasher()
+	wsk := h.sk
+	addrs := h.addrs
+	copy(addrs, zero64[:32])
 	addrs.set(adrLayer, m.layer)
 	addrs.setTree(m.tree)
-	addrs.set(adrOTS, m.Leaf)
-	m.priv.newWotsPrivKey(addrs, wsk)
-	sig := wsk.sign(hmsg, m.priv.pubPRF, addrs)
-	return &xmssSigBody{
-		sig:  sig,
-		auth: m.auth,
+	addrs.set(adrOTS, leaf)
+	h.newWotsPrivKey(m.priv, addrs, wsk)
+	wsk.signTo(hmsg, m.priv.pubPRF, addrs, out, m.priv.executor())
+	for _, sk := range wsk {
+		wipe(sk)
 	}
+	putHasher(h)
 }
 
 //IndexFromSig returns index of merkle from the signature bsig.
 func IndexFromSig(bsig []byte) (uint32, error) {
 	if len(bsig) < 4 {
-		return 0, errors.New("invalid signature length")
+		return 0, ValidationError("invalid signature length")
 	}
 	return binary.BigEndian.Uint32(bsig), nil
 }
 
 //Verify verifies msg by XMSS.
 func Verify(bsig, msg, bpk []byte) bool {
-	pk, err := DeserializePK(bpk)
-	if err != nil {
+	return VerifyWith(nil, bsig, msg, bpk)
+}
+
+//VerifyWith verifies msg by XMSS, running tasks by e.
+//DefaultExecutor is used if e is nil.
+func VerifyWith(e Executor, bsig, msg, bpk []byte) bool {
+	if checkSelfTest() != nil {
 		return false
 	}
-	sig, err := bytes2sig(bsig, pk.Height)
+	return verify(e, bsig, msg, bpk)
+}
+
+func verify(e Executor, bsig, msg, bpk []byte) bool {
+	pk, err := parsePK(bpk)
 	if err != nil {
 		return false
 	}
-	prf := newPRF(pk.Seed)
-	r := make([]byte, 32*3)
-	copy(r, sig.r)
+	return verifyPK(e, bsig, msg, &pk)
+}
+
+//verifyPK verifies msg by XMSS with pk without allocating.
+func verifyPK(e Executor, bsig, msg []byte, pk *PublicKey) bool {
+	if len(bsig) != 4+n+(wlen+int(pk.Height))*n {
+		return false
+	}
+	idx := binary.BigEndian.Uint32(bsig)
+	if uint64(idx) >= 1<<pk.Height {
+		return false
+	}
+	h := getHasher()
+	prf := h.wprf
+	prf.init(pk.Seed, h.buf)
+	var r [32 * 3]byte
+	copy(r[:], bsig[4:4+n])
 	copy(r[32:], pk.Root)
-	binary.BigEndian.PutUint32(r[64+28:], sig.idx)
-	hmsg := hashMsg(r, msg)
-	root := rootFromSig(sig.idx, hmsg, sig.xmssSigBody, prf, 0, 0)
-	return bytes.Equal(root, pk.Root)
+	binary.BigEndian.PutUint32(r[64+28:], idx)
+	var hmsg, root [n]byte
+	h.hashMsg(r[:], msg, hmsg[:])
+	body := h.sigBody(bsig[4+n:], int(pk.Height))
+	rootFromSig(idx, hmsg[:], body, prf, 0, 0, executorOr(e), root[:])
+	h.clearBody()
+	prf.destroy()
+	putHasher(h)
+	return bytes.Equal(root[:], pk.Root)
 }
 
-func rootFromSig(idx uint32, hmsg []byte, body *xmssSigBody, prf *prf, layer uint32, tree uint64) []byte {
-	addrs := make(addr, 32)
+//rootFromSig computes the root from the signature body of hmsg at idx into out.
+func rootFromSig(idx uint32, hmsg []byte, body *xmssSigBody, prf *prf, layer uint32, tree uint64, e Executor, out []byte) {
+	h := getHasher()
+	addrs := h.addrs
+	copy(addrs, zero64[:32])
 	addrs.set(adrLayer, layer)
 	addrs.setTree(tree)
 	addrs.set(adrOTS, idx)
-	pkOTS := body.sig.pubkey(hmsg, prf, addrs)
+	body.sig.pubkeyTo(hmsg, prf, addrs, h.pk, e)
 	addrs.set(adrType, 1)
 	addrs.set(adrLtree, idx)
-	node0 := pkOTS.ltree(prf, addrs)
+	node0 := h.pk.ltreeWith(h.lanes, prf, addrs)
+	copy(out, rootFromAuth(idx, node0, body.auth, prf, layer, tree))
+	putHasher(h)
+}
+
+//rootFromAuth computes the root from node0 of the leaf at idx and its auth path.
+//node0 is overwritten.
+func rootFromAuth(idx uint32, node0 []byte, auth [][]byte, prf *prf, layer uint32, tree uint64) []byte {
+	addrs := make(addr, 32)
+	addrs.set(adrLayer, layer)
+	addrs.setTree(tree)
 	addrs.set(adrType, 2)
-	addrs.set(adrLtree, 0)
 	var k uint32
-	for k = 0; k < uint32(len(body.auth)); k++ {
+	for k = 0; k < uint32(len(auth)); k++ {
 		addrs.set(adrHeight, k)
 		addrs.set(adrIndex, idx>>1)
 		if idx&0x1 == 0 {
-			randHash(node0, body.auth[k], prf, addrs, node0)
+			randHash(node0, auth[k], prf, addrs, node0)
 		} else {
-			randHash(body.auth[k], node0, prf, addrs, node0)
+			randHash(auth[k], node0, prf, addrs, node0)
 		}
 		idx >>= 1
 	}
@@ -38,7 +38,10 @@ func TestXMSS2(t *testing.T) {
 	msg := []byte("This is a test for XMSS.")
 	var pre []byte
 	for i := 0; i < 1<<10; i++ {
-		sig := mer.Sign(msg)
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
 		if !Verify(sig, msg, mer.PublicKey()) {
 			t.Error("XMSS sig is incorrect")
 		}
@@ -56,7 +59,10 @@ func TestXMSS3(t *testing.T) {
 	seed := generateSeed()
 	mer := NewMerkle(10, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	msg[0] = 0
 	if Verify(sig, msg, mer.PublicKey()) {
 		t.Error("XMSS sig is incorrect")
@@ -69,7 +75,10 @@ func TestXMSS4(t *testing.T) {
 	seed := generateSeed()
 	mer := NewMerkle(2, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !Verify(sig, msg, mer.PublicKey()) {
 		t.Error("XMSS sig is incorrect")
 	}
@@ -85,13 +94,148 @@ func TestXMSS16(t *testing.T) {
 	seed := generateSeed()
 	mer := NewMerkle(16, seed)
 	msg := []byte("This is a test for XMSS height=16.")
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !Verify(sig, msg, mer.PublicKey()) {
 		t.Error("XMSS sig is incorrect")
 	}
 	runtime.GOMAXPROCS(npref)
 }
 
+func TestSetLeafNo(t *testing.T) {
+	seed := generateSeed()
+	mer := NewMerkle(5, seed)
+	mer2 := NewMerkle(5, seed)
+	msg := []byte("This is a test for SetLeafNo.")
+	for _, n := range []uint64{0, 3, 4, 8, 17, 30, 31} {
+		for mer.LeafNo() < n {
+			mer.Traverse()
+		}
+		if err := mer2.SetLeafNo(n); err != nil {
+			t.Fatal(err)
+		}
+		for i := range mer.auth {
+			if !bytes.Equal(mer.auth[i], mer2.auth[i]) {
+				t.Error("auth is different", n, i)
+			}
+		}
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := mer2.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, sig2) {
+			t.Error("sig is different", n)
+		}
+		if !Verify(sig2, msg, mer2.PublicKey()) {
+			t.Error("XMSS sig is incorrect", n)
+		}
+	}
+	if err := mer2.SetLeafNo(3); err == nil {
+		t.Error("should not set past index")
+	}
+	if err := mer2.SetLeafNo(33); err == nil {
+		t.Error("should not set out of range")
+	}
+
+	mer3 := NewMerkle(5, seed)
+	if err := mer3.SetLeafNo(9); err != nil {
+		t.Fatal(err)
+	}
+	for i := 9; i < 32; i++ {
+		sig, err := mer3.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(sig, msg, mer3.PublicKey()) {
+			t.Error("XMSS sig is incorrect", i)
+		}
+	}
+}
+
+//countExecutor runs tasks serially and counts calls of Run,
+//which is called once per leaf computed when completing stacks.
+type countExecutor struct {
+	runs int
+}
+
+func (c *countExecutor) Run(n int, f func(i int)) {
+	c.runs++
+	for i := 0; i < n; i++ {
+		f(i)
+	}
+}
+
+//Concurrency returns 2 so that WOTS+ chains are computed through Run.
+func (c *countExecutor) Concurrency() int {
+	return 2
+}
+
+func TestSetLeafNoCost(t *testing.T) {
+	seed := generateSeed()
+	msg := []byte("This is a test for the cost of SetLeafNo.")
+	mer := NewMerkle(10, seed)
+	e := &countExecutor{}
+	mer.SetExecutor(e)
+	//auth nodes cost 2^10-1 leaves, and stacks outside the tree cost nothing.
+	c := mer.rebuildCost(600)
+	if err := mer.SetLeafNo(600); err != nil {
+		t.Fatal(err)
+	}
+	if e.runs >= 3<<9 {
+		t.Error("too many leaves are computed", e.runs)
+	}
+	if uint64(e.runs) != c {
+		t.Error("invalid estimated cost", c, e.runs)
+	}
+	for i := 0; i < 3; i++ {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(sig, msg, mer.PublicKey()) {
+			t.Error("XMSS sig is incorrect", i)
+		}
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	seed := generateSeed()
+	mer := NewMerkle(4, seed)
+	msg := []byte("This is a test for Destroy.")
+	if _, err := mer.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	mer.Destroy()
+	for _, p := range []*prf{mer.priv.wotsPRF, mer.priv.msgPRF} {
+		if !bytes.Equal(p.seed, make([]byte, 32)) {
+			t.Error("seed is not wiped")
+		}
+		for _, b := range p.block1 {
+			if b != 0 {
+				t.Error("midstate is not wiped")
+			}
+		}
+	}
+	if _, err := mer.Sign(msg); err != ErrDestroyed {
+		t.Error("should not sign after destroyed", err)
+	}
+	if err := mer.SetLeafNo(3); err != ErrDestroyed {
+		t.Error("should not set leaf no after destroyed", err)
+	}
+	if _, err := mer.Split(3); err != ErrDestroyed {
+		t.Error("should not split after destroyed", err)
+	}
+	if _, err := json.Marshal(mer); err == nil {
+		t.Error("should not marshal after destroyed")
+	}
+}
+
 func TestXMSSMarshal(t *testing.T) {
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
@@ -108,8 +252,14 @@ func TestXMSSMarshal(t *testing.T) {
 	if err = json.Unmarshal(dat, &mer2); err != nil {
 		t.Error(err)
 	}
-	sig := mer.Sign(msg)
-	sig2 := mer2.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := mer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, sig2) {
 		t.Error("invlaid json marshal")
 	}
@@ -123,8 +273,14 @@ func TestXMSSMarshal(t *testing.T) {
 	if err = msgpack.Unmarshal(mdat, &mmer); err != nil {
 		t.Error(err)
 	}
-	sig = mer.Sign(msg)
-	msig := mmer.Sign(msg)
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msig, err := mmer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, msig) {
 		t.Error("invlaid msgpack marshal")
 	}
@@ -140,8 +296,14 @@ func TestXMSSMarshal(t *testing.T) {
 	if err := dec.Decode(&mmer2); err != nil {
 		t.Fatal(err)
 	}
-	sig = mer.Sign(msg)
-	msig2 := mmer2.Sign(msg)
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msig2, err := mmer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, msig2) {
 		t.Error("invlaid msgpack marshal")
 	}
@@ -150,6 +312,7 @@ func TestXMSSMarshal(t *testing.T) {
 }
 
 func BenchmarkXMSS16(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
@@ -158,6 +321,7 @@ func BenchmarkXMSS16(b *testing.B) {
 	runtime.GOMAXPROCS(npref)
 }
 func BenchmarkXMSS16Sign(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
@@ -165,17 +329,23 @@ func BenchmarkXMSS16Sign(b *testing.B) {
 	msg := []byte("This is a test for XMSS.")
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = mer.Sign(msg)
+		if _, err := mer.Sign(msg); err != nil {
+			b.Fatal(err)
+		}
 	}
 	runtime.GOMAXPROCS(npref)
 }
 func BenchmarkXMSS16Veri(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
 	mer := NewMerkle(16, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		b.Fatal(err)
+	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		Verify(sig, msg, mer.PublicKey())
@@ -184,6 +354,7 @@ func BenchmarkXMSS16Veri(b *testing.B) {
 }
 
 func BenchmarkXMSS20(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
@@ -193,6 +364,7 @@ func BenchmarkXMSS20(b *testing.B) {
 }
 
 func BenchmarkXMSS10(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
@@ -203,6 +375,7 @@ func BenchmarkXMSS10(b *testing.B) {
 	runtime.GOMAXPROCS(npref)
 }
 func BenchmarkXMSS10Sign(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
@@ -210,17 +383,23 @@ func BenchmarkXMSS10Sign(b *testing.B) {
 	msg := []byte("This is a test for XMSS.")
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = mer.Sign(msg)
+		if _, err := mer.Sign(msg); err != nil {
+			b.Fatal(err)
+		}
 	}
 	runtime.GOMAXPROCS(npref)
 }
 func BenchmarkXMSS10Veri(b *testing.B) {
+	b.ReportAllocs()
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
 	seed := generateSeed()
 	mer := NewMerkle(10, seed)
 	msg := []byte("This is a test for XMSS.")
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		b.Fatal(err)
+	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		Verify(sig, msg, mer.PublicKey())
@@ -26,6 +26,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"math"
 
 	sha256 "github.com/AidosKuneen/sha256-simd"
 	"github.com/vmihailenco/msgpack"
@@ -37,17 +38,44 @@ type PrivKeyMT struct {
 	merkle []*Merkle
 	h      uint32
 	d      uint32
+	//start and end are the range of indices [start, end) which this key is allowed to use.
+	start uint64
+	end   uint64
+	//tag is MAC of the range to detect tampering.
+	tag []byte
+	//checked is the range verified last, not to compute MAC for each signature.
+	checked rangeCheck
+	//next holds builders of the next trees in each layer. They are not serialized.
+	next []*nextTree
+	//sigs caches signatures of upper layers, which change only when the lower tree changes.
+	sigs []*cachedSig
+	//epoch increases whenever index changes.
+	epoch uint64
+	//counter records the epoch outside if not nil.
+	counter Counter
+	//guard is true if signatures are verified before returning them.
+	guard bool
+	//suspect is true if a signature failed verification by the guard.
+	suspect bool
+}
+
+//cachedSig is a signature by the leaf in the tree of a layer.
+type cachedSig struct {
+	tree uint64
+	leaf uint32
+	//valid is false until body is signed.
+	valid bool
+	//body is the WOTS+ signature and the auth path in bytes.
+	body []byte
 }
 
 //NewPrivKeyMT returns XMSS^MT private key.
 func NewPrivKeyMT(seed []byte, h, d uint32) (*PrivKeyMT, error) {
-	if h%d != 0 || h%20 != 0 || h == 0 || d == 0 || h/20 > 15 || d > 15 {
-		return nil, errors.New("invalid h or d")
+	if err := checkSelfTest(); err != nil {
+		return nil, err
 	}
-	p := PrivKeyMT{
-		merkle: make([]*Merkle, d),
-		h:      h,
-		d:      d,
+	if _, err := PublickeyMTHeader(h, d); err != nil {
+		return nil, err
 	}
 	mac := hmac.New(sha256.New, seed)
 	if _, err := mac.Write([]byte{1}); err != nil {
@@ -64,8 +92,23 @@ func NewPrivKeyMT(seed []byte, h, d uint32) (*PrivKeyMT, error) {
 		panic(err)
 	}
 	pubSeed := mac.Sum(nil)
-	p.merkle[d-1] = newMerkle(h/d, wotsSeed, msgSeed, pubSeed, d-1, 0)
-	return &p, nil
+	p := newPrivKeyMT(h, d, wotsSeed, msgSeed, pubSeed)
+	wipe(wotsSeed)
+	wipe(msgSeed)
+	return p, nil
+}
+
+//newPrivKeyMT makes XMSS^MT private key from seeds (SK_SEED, SK_PRF and SEED in RFC 8391).
+func newPrivKeyMT(h, d uint32, wotsSeed, msgSeed, pubSeed []byte) *PrivKeyMT {
+	p := &PrivKeyMT{
+		merkle: make([]*Merkle, d),
+		h:      h,
+		d:      d,
+		end:    numIndices(h),
+	}
+	p.merkle[d-1] = newMerkle(h/d, wotsSeed, msgSeed, pubSeed, d-1, 0, nil)
+	p.tag = p.rangeTag()
+	return p
 }
 
 //LeafNo returns the leaf no in xmss^mt.
@@ -73,15 +116,94 @@ func (p *PrivKeyMT) LeafNo() uint64 {
 	return p.index
 }
 
-//SetLeafNo sets the leaf no in merkle and refresh authes..
+//SetLeafNo moves the leaf no forward to n and builds trees of all layers for n,
+//so that next Sign doesn't need to build them.
 func (p *PrivKeyMT) SetLeafNo(n uint64) error {
-	if p.index < n {
-		return errors.New("should not set past index")
+	if p.destroyed() {
+		return ErrDestroyed
+	}
+	if p.index > n {
+		return errors.New("must not set past index")
+	}
+	if n > p.end {
+		return errors.New("index is out of range")
+	}
+	if n < p.end {
+		if err := p.refresh(n); err != nil {
+			return err
+		}
 	}
 	p.index = n
+	p.epoch++
+	return nil
+}
+
+//refresh makes trees of all layers ready to sign with index.
+func (p *PrivKeyMT) refresh(index uint64) error {
+	mpriv := p.merkle[p.d-1].priv
+	mask := uint64((1 << (p.h / p.d)) - 1)
+	idxTree := index
+	if len(p.next) != int(p.d) {
+		p.next = make([]*nextTree, p.d)
+	}
+	for j := uint32(0); j < p.d; j++ {
+		idxLeaf := uint32(idxTree & mask)
+		idxTree = idxTree >> (p.h / p.d)
+		if p.merkle[j] == nil || p.merkle[j].tree != idxTree {
+			if p.merkle[j] != nil && j != p.d-1 {
+				p.merkle[j].Destroy()
+			}
+			if b := p.next[j]; b != nil && b.m.tree == idxTree {
+				p.merkle[j] = b.finish()
+			} else {
+				if b != nil {
+					b.m.Destroy()
+				}
+				p.merkle[j] = newMerkle(p.h/p.d, mpriv.wotsPRF.seed, mpriv.msgPRF.seed, mpriv.pubPRF.seed, j, idxTree, mpriv.exec)
+			}
+			p.next[j] = nil
+		}
+		if err := p.merkle[j].SetLeafNo(uint64(idxLeaf)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (p *PrivKeyMT) destroyed() bool {
+	return p.merkle[p.d-1].priv.destroyed
+}
+
+//SetExecutor sets the Executor which runs tasks to build trees and sign.
+//DefaultExecutor is used if e is nil.
+func (p *PrivKeyMT) SetExecutor(e Executor) {
+	for _, m := range p.merkle {
+		if m != nil {
+			m.SetExecutor(e)
+		}
+	}
+	for _, b := range p.next {
+		if b != nil {
+			b.m.SetExecutor(e)
+		}
+	}
+}
+
+//Destroy wipes all secrets in PrivKeyMT.
+//PrivKeyMT cannot be used to sign after calling this.
+func (p *PrivKeyMT) Destroy() {
+	for _, m := range p.merkle {
+		if m != nil {
+			m.Destroy()
+		}
+	}
+	for _, b := range p.next {
+		if b != nil {
+			b.m.Destroy()
+		}
+	}
+}
+
 //PublicKey returns public key (merkle root) of XMSS^MT
 func (p *PrivKeyMT) PublicKey() []byte {
 	priv := p.merkle[p.d-1].priv
@@ -93,86 +215,80 @@ func (p *PrivKeyMT) PublicKey() []byte {
 	return key
 }
 
-type xmssMTSig struct {
-	idx  uint64
-	r    []byte
-	sigs []*xmssSigBody
-}
-
-func (x *xmssMTSig) bytes() []byte {
-	d := len(x.sigs)
-	h := len(x.sigs[0].auth) * d
-	bytesPerLayer := (wlen + h/d) * n
-	sigSize := 8 + n + (wlen+h/d)*n*d
-	sig := make([]byte, sigSize)
-	binary.BigEndian.PutUint64(sig, x.idx)
-	copy(sig[8:], x.r)
-	for i, body := range x.sigs {
-		copy(sig[8+n+bytesPerLayer*i:], body.bytes())
-	}
-	return sig
-}
-
-func bytes2MTsig(b []byte, d, h uint32) (*xmssMTSig, error) {
-	bytesPerLayer := (wlen + h/d) * n
-	sigSize := 8 + n + bytesPerLayer*d
-	if uint32(len(b)) != sigSize {
-		return nil, errors.New("invalid length of bytes")
+//Sign signs by XMSS with XMSS^MT.
+//It returns an error if no index is left in the range of p.
+func (p *PrivKeyMT) Sign(msg []byte) ([]byte, error) {
+	if p.destroyed() {
+		return nil, ErrDestroyed
 	}
-	sig := &xmssMTSig{
-		idx:  binary.BigEndian.Uint64(b),
-		r:    b[8 : 8+n],
-		sigs: make([]*xmssSigBody, d),
+	if p.suspect {
+		return nil, ErrSuspect
 	}
-	for i := range sig.sigs {
-		start := 8 + n + uint32(i)*bytesPerLayer
-		sig.sigs[i] = bytes2sigBody(b[start:start+bytesPerLayer], int(h/d))
+	if err := checkSelfTest(); err != nil {
+		return nil, err
 	}
-	return sig, nil
-}
-
-//Sign signs by XMSS with XMSS^MT.
-func (p *PrivKeyMT) Sign(msg []byte) []byte {
-	index := make([]byte, 32)
+	if err := p.checkRange(); err != nil {
+		return nil, err
+	}
+	if p.index < p.start || p.index >= p.end {
+		return nil, errors.New("index is out of range")
+	}
+	hh := p.h / p.d
+	bytesPerLayer := (wlen + hh) * n
+	bsig := make([]byte, 8+n+bytesPerLayer*p.d)
+	binary.BigEndian.PutUint64(bsig, p.index)
+	var index [32]byte
 	binary.BigEndian.PutUint64(index[24:], p.index)
 	mpriv := p.merkle[p.d-1].priv
-	r := make([]byte, 32*3)
-	mpriv.msgPRF.sum(index, r)
+	var r [32 * 3]byte
+	mpriv.msgPRF.sum(index[:], r[:])
 	copy(r[32:], mpriv.root)
-	copy(r[64:], index)
-	hmsg := hashMsg(r, msg)
-	sig := &xmssMTSig{
-		idx:  p.index,
-		r:    r[:32],
-		sigs: make([]*xmssSigBody, p.d),
-	}
-	mask := uint64((1 << (p.h / p.d)) - 1)
-	idxTree := p.index >> (p.h / p.d)
-	idxLeaf := uint32(p.index & mask)
-	if p.merkle[0] == nil || p.merkle[0].tree != idxTree {
-		p.merkle[0] = newMerkle(p.h/p.d, mpriv.wotsPRF.seed, mpriv.msgPRF.seed, mpriv.pubPRF.seed, 0, idxTree)
+	copy(r[64:], index[:])
+	copy(bsig[8:], r[:32])
+	var hmsg [n]byte
+	h := getHasher()
+	h.hashMsg(r[:], msg, hmsg[:])
+	if err := p.refresh(p.index); err != nil {
+		putHasher(h)
+		return nil, err
 	}
-	for p.merkle[0].Leaf < idxLeaf {
-		p.merkle[0].Traverse()
+	p.merkle[0].signBody(hmsg[:], h.sigBody(bsig[8+n:], int(hh)))
+	h.clearBody()
+	//traversing doesn't allocate, unlike rebuilding auth by refresh.
+	p.merkle[0].Traverse()
+	if len(p.sigs) != int(p.d) {
+		p.sigs = make([]*cachedSig, p.d)
 	}
-	sig.sigs[0] = p.merkle[0].sign(hmsg)
 	root := p.merkle[0].priv.root
-
 	for j := uint32(1); j < p.d; j++ {
-		idxLeaf := uint32(idxTree & mask)
-		idxTree = idxTree >> (p.h / p.d)
-		if p.merkle[j] == nil || p.merkle[j].tree != idxTree {
-			p.merkle[j] = newMerkle(p.h/p.d, mpriv.wotsPRF.seed, mpriv.msgPRF.seed, mpriv.pubPRF.seed, j, idxTree)
+		m := p.merkle[j]
+		c := p.sigs[j]
+		if c == nil {
+			c = &cachedSig{
+				body: make([]byte, bytesPerLayer),
+			}
+			p.sigs[j] = c
 		}
-		for p.merkle[j].Leaf < idxLeaf {
-			p.merkle[j].Traverse()
+		if !c.valid || c.tree != m.tree || c.leaf != m.Leaf {
+			c.tree, c.leaf, c.valid = m.tree, m.Leaf, true
+			m.signBody(root, h.sigBody(c.body, int(hh)))
+			h.clearBody()
 		}
-		sig.sigs[j] = p.merkle[j].sign(root)
-		root = p.merkle[j].priv.root
+		copy(bsig[8+n+bytesPerLayer*j:], c.body)
+		root = m.priv.root
 	}
+	putHasher(h)
 
 	p.index++
-	return sig.bytes()
+	p.epoch++
+	p.buildNext()
+	if err := p.commit(); err != nil {
+		return nil, err
+	}
+	if err := p.checkSig(bsig, msg); err != nil {
+		return nil, err
+	}
+	return bsig, nil
 }
 
 //PublicKeyMT for xmss^MT
@@ -183,11 +299,34 @@ type PublicKeyMT struct {
 	Seed []byte
 }
 
-//PublickeyMTHeader returns first 1 byte of public key of XMSS^MT
+//numIndices returns the number of indices of XMSS^MT with height h.
+//It returns 2^64-1 for h >= 64, losing the last index.
+func numIndices(h uint32) uint64 {
+	if h >= 64 {
+		return math.MaxUint64
+	}
+	return 1 << h
+}
+
+//mtLayers are the numbers of layers for each height of XMSS^MT
+//in the parameter sets of RFC 8391.
+var mtLayers = map[uint32][]uint32{
+	20: {2, 4},
+	40: {2, 4, 8},
+	60: {3, 6, 12},
+}
+
+//PublickeyMTHeader returns first 1 byte of public key of XMSS^MT.
+//h and d must be one of the parameter sets in RFC 8391.
 func PublickeyMTHeader(h, d uint32) (byte, error) {
-	if h%d != 0 || h%20 != 0 || h == 0 || d == 0 ||
-		h/20 > 15 || d > 15 {
-		return 0, errors.New("invalid h or d")
+	valid := false
+	for _, dd := range mtLayers[h] {
+		if d == dd {
+			valid = true
+		}
+	}
+	if !valid {
+		return 0, ValidationError("invalid h or d")
 	}
 	header := byte(h) / 20
 	header = (header << 4) | byte(d)
@@ -211,13 +350,24 @@ func (p *PublicKeyMT) Serialize() ([]byte, error) {
 
 //DeserializeMT deserialized bytes to XMSS^MT PublicKey.
 func DeserializeMT(key []byte) (*PublicKeyMT, error) {
+	pk, err := parsePKMT(key)
+	if err != nil {
+		return nil, err
+	}
+	return &pk, nil
+}
+
+func parsePKMT(key []byte) (PublicKeyMT, error) {
 	if len(key) != 65 {
-		return nil, errors.New("invalid bytes length")
+		return PublicKeyMT{}, ValidationError("invalid bytes length")
 	}
 	h := uint32(key[0] & 0xf0)
 	h = (h >> 4) * 20
 	d := uint32(key[0] & 0x0f)
-	return &PublicKeyMT{
+	if _, err := PublickeyMTHeader(h, d); err != nil {
+		return PublicKeyMT{}, err
+	}
+	return PublicKeyMT{
 		H:    h,
 		D:    d,
 		Root: key[1:33],
@@ -227,32 +377,73 @@ func DeserializeMT(key []byte) (*PublicKeyMT, error) {
 
 //VerifyMT verifies msg by XMSS^MT.
 func VerifyMT(bsig, msg, bpk []byte) bool {
-	pk, err := DeserializeMT(bpk)
-	if err != nil {
+	return verifyMT(nil, bsig, msg, bpk, nil)
+}
+
+//VerifyMTWith verifies msg by XMSS^MT, running tasks by e.
+//DefaultExecutor is used if e is nil.
+func VerifyMTWith(e Executor, bsig, msg, bpk []byte) bool {
+	return verifyMT(e, bsig, msg, bpk, nil)
+}
+
+//verifyMT verifies msg by XMSS^MT.
+//If c is not nil, verification stops at a root of a subtree authenticated before,
+//and roots of subtrees are stored into c when msg is verified.
+func verifyMT(e Executor, bsig, msg, bpk []byte, c *VerifyCache) bool {
+	if checkSelfTest() != nil {
 		return false
 	}
-	sig, err := bytes2MTsig(bsig, pk.D, pk.H)
+	pk, err := parsePKMT(bpk)
 	if err != nil {
 		return false
 	}
-	r := make([]byte, 32*3)
-	copy(r, sig.r)
+	hh := pk.H / pk.D
+	bytesPerLayer := (wlen + hh) * n
+	if uint32(len(bsig)) != 8+n+bytesPerLayer*pk.D {
+		return false
+	}
+	idx := binary.BigEndian.Uint64(bsig)
+	if pk.H < 64 && idx >= 1<<pk.H {
+		return false
+	}
+	h := getHasher()
+	prf := h.wprf
+	prf.init(pk.Seed, h.buf)
+	var r [32 * 3]byte
+	copy(r[:], bsig[8:8+n])
 	copy(r[32:], pk.Root)
-	binary.BigEndian.PutUint64(r[64+24:], sig.idx)
-	hmsg := hashMsg(r, msg)
-	prf := newPRF(pk.Seed)
+	binary.BigEndian.PutUint64(r[64+24:], idx)
+	var node [n]byte
+	h.hashMsg(r[:], msg, node[:])
 
-	mask := uint64((1 << (pk.H / pk.D)) - 1)
-	idxTree := sig.idx >> (pk.H / pk.D)
-	idxLeaf := uint32(sig.idx & mask)
-	node := rootFromSig(idxLeaf, hmsg, sig.sigs[0], prf, 0, idxTree)
-
-	for j := uint32(1); j < pk.D; j++ {
+	mask := uint64((1 << hh) - 1)
+	idxTree := idx
+	var roots []*verifiedRoot
+	e = executorOr(e)
+	ok := true
+	for j := uint32(0); j < pk.D; j++ {
+		if c != nil && j > 0 {
+			vr := &verifiedRoot{layer: j - 1, tree: idxTree, root: append([]byte(nil), node[:]...)}
+			if c.has(bpk, vr) {
+				break
+			}
+			roots = append(roots, vr)
+		}
 		idxLeaf := uint32(idxTree & mask)
-		idxTree = idxTree >> (pk.H / pk.D)
-		node = rootFromSig(idxLeaf, node, sig.sigs[j], prf, j, idxTree)
+		idxTree = idxTree >> hh
+		body := h.sigBody(bsig[8+n+bytesPerLayer*j:], int(hh))
+		rootFromSig(idxLeaf, node[:], body, prf, j, idxTree, e, node[:])
+		h.clearBody()
+		if j == pk.D-1 {
+			ok = bytes.Equal(pk.Root, node[:])
+		}
 	}
-	return bytes.Equal(pk.Root, node)
+	prf.destroy()
+	putHasher(h)
+	if ok && c != nil {
+		c.add(bpk, roots)
+	}
+	return ok
 }
 
 type privKeyMT struct {
@@ -260,41 +451,85 @@ type privKeyMT struct {
 	Merkle []*Merkle
 	H      uint32
 	D      uint32
+	Start  uint64
+	End    uint64
+	Tag    []byte
+	Epoch  uint64
+	//Suspect is nil in states made before the flag was stored.
+	Suspect *bool
+	MAC     []byte
+	//version is the version of the binary encoding, or 0 if not decoded from binary.
+	version byte
+}
+
+//macVersion returns the binary version of the body which MAC of s covers.
+func (s *privKeyMT) macVersion() byte {
+	switch {
+	case s.version != 0:
+		return s.version
+	case s.Suspect == nil:
+		return 2
+	}
+	return binaryVersion
 }
 
 func (p *PrivKeyMT) exports() *privKeyMT {
+	suspect := p.suspect
 	return &privKeyMT{
-		Index:  p.index,
-		Merkle: p.merkle,
-		H:      p.h,
-		D:      p.d,
+		Index:   p.index,
+		Merkle:  p.merkle,
+		H:       p.h,
+		D:       p.d,
+		Start:   p.start,
+		End:     p.end,
+		Tag:     p.tag,
+		Epoch:   p.epoch,
+		Suspect: &suspect,
+		MAC:     p.mac(binaryVersion),
 	}
 }
 
-func (p *PrivKeyMT) imports(s *privKeyMT) {
+func (p *PrivKeyMT) imports(s *privKeyMT) error {
 	p.index = s.Index
 	p.merkle = s.Merkle
 	p.h = s.H
 	p.d = s.D
+	p.start = s.Start
+	p.end = s.End
+	p.tag = s.Tag
+	p.epoch = s.Epoch
+	p.suspect = s.Suspect != nil && *s.Suspect
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if err := p.checkRange(); err != nil {
+		return err
+	}
+	return p.checkMAC(s.MAC, s.macVersion())
 }
 
 //MarshalJSON  marshals PrivKeyMT into valid JSON.
 func (p *PrivKeyMT) MarshalJSON() ([]byte, error) {
+	if p.destroyed() {
+		return nil, ErrDestroyed
+	}
 	return json.Marshal(p.exports())
 }
 
 //UnmarshalJSON  unmarshals JSON to PrivKeyMT.
 func (p *PrivKeyMT) UnmarshalJSON(b []byte) error {
 	var s privKeyMT
-	err := json.Unmarshal(b, &s)
-	if err == nil {
-		p.imports(&s)
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
 	}
-	return err
+	return p.imports(&s)
 }
 
 //EncodeMsgpack  marshals PrivKeyMT into valid msgpack.
 func (p *PrivKeyMT) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if p.destroyed() {
+		return ErrDestroyed
+	}
 	return enc.Encode(p.exports())
 }
 
@@ -304,6 +539,5 @@ func (p *PrivKeyMT) DecodeMsgpack(dec *msgpack.Decoder) error {
 	if err := dec.Decode(&s); err != nil {
 		return err
 	}
-	p.imports(&s)
-	return nil
+	return p.imports(&s)
 }
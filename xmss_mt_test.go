@@ -42,7 +42,10 @@ func TestXMSSMT(t *testing.T) {
 	msg := []byte("This is a test for XMSS.")
 	var pre []byte
 	for i := 0; i < 100; i++ {
-		sig := mer.Sign(msg)
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
 		if !VerifyMT(sig, msg, mer.PublicKey()) {
 			t.Error("XMSS^MT sig is incorrect")
 		}
@@ -53,7 +56,10 @@ func TestXMSSMT(t *testing.T) {
 	}
 	mer.index = 1<<32 + 223
 	for i := 0; i < 100; i++ {
-		sig := mer.Sign(msg)
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
 		if !VerifyMT(sig, msg, mer.PublicKey()) {
 			t.Error("XMSS^MT sig is incorrect")
 		}
@@ -725,13 +731,17 @@ func TestXMSSMT2(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	mer.merkle[3] = newMerkle(10, wotsSeed, msgSeed, pubSeed, 3, 0)
+	mer.merkle[3] = newMerkle(10, wotsSeed, msgSeed, pubSeed, 3, 0, nil)
+	mer.tag = mer.rangeTag()
 	if !bytes.Equal(pubkey, mer.merkle[3].priv.root) {
 		t.Error("should be equal")
 	}
 	msg := []byte("This is a test for XMSS.")
 	msg = append(msg, 0x0a)
-	sig := mer.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, csig) {
 		t.Error("should be equal", hex.EncodeToString(sig))
 		t.Error(hex.EncodeToString(csig))
@@ -745,7 +755,10 @@ func TestXMSSMT2(t *testing.T) {
 		t.Error("XMSS^MT sig is incorrect")
 	}
 	mer.index = 1<<33 + 123
-	sig = mer.Sign(msg)
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, csig2) {
 		t.Error("should be equal", hex.EncodeToString(sig))
 	}
@@ -756,6 +769,67 @@ func TestXMSSMT2(t *testing.T) {
 
 }
 
+func TestXMSSMTSetLeafNo(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for SetLeafNo of XMSS^MT.")
+	for _, n := range []uint64{5, 6, 1000, 1<<15 + 3} {
+		if err = mer.SetLeafNo(n); err != nil {
+			t.Fatal(err)
+		}
+		if mer.merkle[0].tree != n>>5 || mer.merkle[0].LeafNo() != n&31 {
+			t.Error("tree of layer 0 is not built", n)
+		}
+		if mer.merkle[1].tree != n>>10 || mer.merkle[1].LeafNo() != (n>>5)&31 {
+			t.Error("tree of layer 1 is not built", n)
+		}
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMT(sig, msg, mer.PublicKey()) {
+			t.Error("XMSS^MT sig is incorrect", n)
+		}
+	}
+	if err := mer.SetLeafNo(6); err == nil {
+		t.Error("should not set past index")
+	}
+	if err := mer.SetLeafNo(1<<20 + 1); err == nil {
+		t.Error("should not set out of range")
+	}
+}
+
+func TestXMSSMTDestroy(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for Destroy of XMSS^MT.")
+	if _, err = mer.Sign(msg); err != nil {
+		t.Fatal(err)
+	}
+	mer.Destroy()
+	for _, m := range mer.merkle {
+		if !bytes.Equal(m.priv.wotsPRF.seed, make([]byte, 32)) ||
+			!bytes.Equal(m.priv.msgPRF.seed, make([]byte, 32)) {
+			t.Error("seed is not wiped")
+		}
+	}
+	if _, err := mer.Sign(msg); err != ErrDestroyed {
+		t.Error("should not sign after destroyed", err)
+	}
+	if err := mer.SetLeafNo(3); err != ErrDestroyed {
+		t.Error("should not set leaf no after destroyed", err)
+	}
+	if _, err := json.Marshal(mer); err == nil {
+		t.Error("should not marshal after destroyed")
+	}
+}
+
 func TestXMSSMTMarshal(t *testing.T) {
 	n := numcpu.NumCPU()
 	npref := runtime.GOMAXPROCS(n)
@@ -773,8 +847,14 @@ func TestXMSSMTMarshal(t *testing.T) {
 	if err = json.Unmarshal(dat, &mer2); err != nil {
 		t.Error(err)
 	}
-	sig := mer.Sign(msg)
-	sig2 := mer2.Sign(msg)
+	sig, err := mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := mer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, sig2) {
 		t.Error("invlaid json marshal")
 	}
@@ -787,8 +867,14 @@ func TestXMSSMTMarshal(t *testing.T) {
 	if err = msgpack.Unmarshal(mdat, &mmer); err != nil {
 		t.Error(err)
 	}
-	sig = mer.Sign(msg)
-	msig := mmer.Sign(msg)
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msig, err := mmer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, msig) {
 		t.Error("invlaid msgpack marshal")
 	}
@@ -803,11 +889,50 @@ func TestXMSSMTMarshal(t *testing.T) {
 	if err := dec.Decode(&mmer2); err != nil {
 		t.Fatal(err)
 	}
-	sig = mer.Sign(msg)
-	msig2 := mmer2.Sign(msg)
+	sig, err = mer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msig2, err := mmer2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if !bytes.Equal(sig, msig2) {
 		t.Error("invlaid msgpack marshal")
 	}
 
 	runtime.GOMAXPROCS(npref)
 }
+
+func TestXMSSMTSigCache(t *testing.T) {
+	seed := generateSeed()
+	mer, err := NewPrivKeyMT(seed, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("This is a test for caching signatures of upper layers.")
+	bytesPerLayer := (wlen + 5) * n
+	var prev []byte
+	for i := 0; i < 40; i++ {
+		sig, err := mer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMT(sig, msg, mer.PublicKey()) {
+			t.Error("XMSS^MT sig is incorrect", i)
+		}
+		upper := sig[8+n+bytesPerLayer:]
+		switch {
+		case i == 0:
+		case i%32 == 0:
+			if bytes.Equal(upper[:bytesPerLayer], prev[:bytesPerLayer]) {
+				t.Error("signature of layer 1 must be changed", i)
+			}
+		default:
+			if !bytes.Equal(upper, prev) {
+				t.Error("signatures of upper layers must be same", i)
+			}
+		}
+		prev = upper
+	}
+}